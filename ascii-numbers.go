@@ -29,7 +29,7 @@ func (p *Packet) AsciiInt() (v int) {
 			}
 		}
 	}
-	p.idx = idx
+	p.setIndex(idx)
 	return
 }
 
@@ -54,6 +54,6 @@ func (p *Packet) AsciiUInt() (v uint) {
 			break
 		}
 	}
-	p.idx = idx
+	p.setIndex(idx)
 	return
 }
@@ -0,0 +1,457 @@
+// Package asn1ber reads ASN.1 BER & DER encoded TLV elements using a *decoder.Packet, as used by SNMP
+// and X.509 certificates.
+package asn1ber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformed = errors.New("malformed element")
+var ErrNotDER = errors.New("not valid DER")
+var ErrUnexpectedTag = errors.New("unexpected tag")
+var ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+
+// DefaultMaxDepth is the deepest indefinite length nesting allowed unless changed
+const DefaultMaxDepth = 32
+
+// Class is the class of a tag
+type Class uint8
+
+// Tag classes
+const (
+	ClassUniversal       Class = 0
+	ClassApplication     Class = 1
+	ClassContextSpecific Class = 2
+	ClassPrivate         Class = 3
+)
+
+// Universal tag numbers
+const (
+	TagBoolean         = 1
+	TagInteger         = 2
+	TagBitString       = 3
+	TagOctetString     = 4
+	TagNull            = 5
+	TagOID             = 6
+	TagEnumerated      = 10
+	TagUTF8String      = 12
+	TagSequence        = 16
+	TagSet             = 17
+	TagPrintableString = 19
+	TagIA5String       = 22
+	TagUTCTime         = 23
+	TagGeneralizedTime = 24
+)
+
+// Element is a single TLV element
+type Element struct {
+	Class       Class
+	Constructed bool
+	Tag         int
+	Offset      int    // The offset of the element's identifier
+	Indefinite  bool   // True if the length was indefinite
+	Value       []byte // The contents, excluding any end-of-contents marker
+	Raw         []byte // The whole encoded element
+	der         bool
+	maxDepth    int
+}
+
+// Is returns true if the element has the given class & tag
+func (e Element) Is(class Class, tag int) bool {
+	return e.Class == class && e.Tag == tag
+}
+
+// Reader reads elements
+type Reader struct {
+	p        *decoder.Packet
+	DER      bool // Reject encodings that are valid BER but not DER
+	MaxDepth int  // The deepest nesting of indefinite length elements allowed
+}
+
+// NewReader returns a Reader of the elements from the packet's current position to its end, the packet's
+// byte order is left as it is
+func NewReader(p *decoder.Packet) *Reader {
+	return &Reader{
+		p:        p,
+		MaxDepth: DefaultMaxDepth,
+	}
+}
+
+// Next returns the next element, or io.EOF at the end of the data.
+// Errors are an *decoder.OffsetError with the offset of the element
+func (r *Reader) Next() (Element, error) {
+	if r.p.RemainingLength() == 0 {
+		return Element{}, io.EOF
+	}
+	e, err := r.element(0)
+	if err == nil && e.Class == ClassUniversal && e.Tag == 0 {
+		r.p.SeekTo(e.Offset)
+		return Element{}, &decoder.OffsetError{Offset: e.Offset, Err: ErrMalformed} // Unexpected end-of-contents
+	}
+	return e, err
+}
+
+// All returns all the remaining elements
+func (r *Reader) All() ([]Element, error) {
+	var v []Element
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return v, err
+		}
+		v = append(v, e)
+	}
+}
+
+func (r *Reader) element(depth int) (Element, error) {
+	p := r.p
+	e := Element{Offset: p.Index(), der: r.DER, maxDepth: r.MaxDepth}
+	fail := func(offset int, err error) (Element, error) {
+		p.Err = nil
+		p.SeekTo(e.Offset)
+		return Element{}, &decoder.OffsetError{Offset: offset, Err: err}
+	}
+
+	// Identifier
+	b := p.Byte()
+	e.Class = Class(b >> 6)
+	e.Constructed = b&0x20 != 0
+	e.Tag = int(b & 0x1f)
+	if e.Tag == 0x1f { // High tag number form
+		e.Tag = 0
+		for i := 0; ; i++ {
+			c := p.Byte()
+			if p.Err != nil {
+				return fail(e.Offset, p.Err)
+			}
+			if (i == 0 && c == 0x80) || i >= 4 {
+				return fail(e.Offset, ErrMalformed) // Padded or too large tag number
+			}
+			e.Tag = e.Tag<<7 | int(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		if r.DER && e.Tag < 0x1f {
+			return fail(e.Offset, ErrNotDER)
+		}
+	}
+
+	// Length
+	lengthOffset := p.Index()
+	l := int(p.Byte())
+	if p.Err != nil {
+		return fail(e.Offset, p.Err)
+	}
+	switch {
+	case l == 0x80:
+		if !e.Constructed {
+			return fail(lengthOffset, ErrMalformed)
+		}
+		if r.DER {
+			return fail(lengthOffset, ErrNotDER)
+		}
+		e.Indefinite = true
+	case l > 0x80:
+		n := l & 0x7f
+		if n > 4 {
+			return fail(lengthOffset, ErrMalformed)
+		}
+		l = 0
+		for i := 0; i < n; i++ {
+			c := p.Byte()
+			if r.DER && i == 0 && c == 0 {
+				return fail(lengthOffset, ErrNotDER)
+			}
+			l = l<<8 | int(c)
+		}
+		if p.Err != nil {
+			return fail(lengthOffset, p.Err)
+		}
+		if r.DER && l < 0x80 {
+			return fail(lengthOffset, ErrNotDER)
+		}
+	}
+
+	// Contents
+	start := p.Index()
+	if e.Indefinite {
+		if depth >= r.MaxDepth {
+			return fail(e.Offset, ErrMaxDepth)
+		}
+		for {
+			end := p.Index()
+			if p.RemainingLength() == 0 {
+				return fail(e.Offset, decoder.ErrReadPastEndData)
+			}
+			c, err := r.element(depth + 1)
+			if err != nil {
+				p.SeekTo(e.Offset)
+				return Element{}, err
+			}
+			if c.Class == ClassUniversal && c.Tag == 0 && !c.Constructed && len(c.Value) == 0 {
+				e.Value = p.PeekBytes()[start:end]
+				break
+			}
+		}
+	} else {
+		if l > p.RemainingLength() {
+			return fail(lengthOffset, decoder.ErrReadPastEndData)
+		}
+		e.Value = p.Bytes(l)
+	}
+	e.Raw = p.PeekBytes()[e.Offset:p.Index()]
+	return e, nil
+}
+
+// Children returns a Reader of the elements within a constructed element, such as a SEQUENCE or SET
+func (e Element) Children() *Reader {
+	r := NewReader(decoder.New(e.Value))
+	r.DER = e.der
+	r.MaxDepth = e.maxDepth
+	return r
+}
+
+// error returns an *decoder.OffsetError at the element
+func (e Element) error(err error) error {
+	return &decoder.OffsetError{Offset: e.Offset, Err: err}
+}
+
+// expect checks the element is a primitive universal type
+func (e Element) expect(tag int) error {
+	if e.Class != ClassUniversal || e.Tag != tag {
+		return e.error(ErrUnexpectedTag)
+	}
+	if e.Constructed {
+		return e.error(ErrMalformed)
+	}
+	return nil
+}
+
+// Bool returns the value of a BOOLEAN
+func (e Element) Bool() (bool, error) {
+	if err := e.expect(TagBoolean); err != nil {
+		return false, err
+	}
+	if len(e.Value) != 1 {
+		return false, e.error(ErrMalformed)
+	}
+	if e.der && e.Value[0] != 0x00 && e.Value[0] != 0xff {
+		return false, e.error(ErrNotDER)
+	}
+	return e.Value[0] != 0, nil
+}
+
+// Null checks the element is a NULL
+func (e Element) Null() error {
+	if err := e.expect(TagNull); err != nil {
+		return err
+	}
+	if len(e.Value) != 0 {
+		return e.error(ErrMalformed)
+	}
+	return nil
+}
+
+// Int64 returns the value of an INTEGER or ENUMERATED that fits in an int64.
+// Implicitly tagged integers can be read with IntegerValue
+func (e Element) Int64() (int64, error) {
+	if e.Class != ClassUniversal || (e.Tag != TagInteger && e.Tag != TagEnumerated) {
+		return 0, e.error(ErrUnexpectedTag)
+	}
+	return e.IntegerValue()
+}
+
+// IntegerValue returns the contents as a two's complement integer that fits in an int64, whatever the tag
+func (e Element) IntegerValue() (int64, error) {
+	if err := e.checkInteger(); err != nil {
+		return 0, err
+	}
+	if len(e.Value) > 8 {
+		return 0, e.error(ErrMalformed)
+	}
+	var v int64
+	for i, c := range e.Value {
+		if i == 0 {
+			v = int64(int8(c))
+			continue
+		}
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+// BigInt returns the value of an INTEGER of any size
+func (e Element) BigInt() (*big.Int, error) {
+	if err := e.expect(TagInteger); err != nil {
+		return nil, err
+	}
+	if err := e.checkInteger(); err != nil {
+		return nil, err
+	}
+	v := new(big.Int).SetBytes(e.Value)
+	if e.Value[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(e.Value))*8))
+	}
+	return v, nil
+}
+
+func (e Element) checkInteger() error {
+	if e.Constructed || len(e.Value) == 0 {
+		return e.error(ErrMalformed)
+	}
+	if e.der && len(e.Value) > 1 &&
+		((e.Value[0] == 0x00 && e.Value[1]&0x80 == 0) || (e.Value[0] == 0xff && e.Value[1]&0x80 != 0)) {
+		return e.error(ErrNotDER) // Not minimally encoded
+	}
+	return nil
+}
+
+// OctetString returns the contents of an OCTET STRING, in BER a constructed OCTET STRING is joined together
+func (e Element) OctetString() ([]byte, error) {
+	if e.Class != ClassUniversal || e.Tag != TagOctetString {
+		return nil, e.error(ErrUnexpectedTag)
+	}
+	if !e.Constructed {
+		return e.Value, nil
+	}
+	if e.der {
+		return nil, e.error(ErrNotDER)
+	}
+	var v []byte
+	r := e.Children()
+	for {
+		c, err := r.Next()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := c.OctetString()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, b...)
+	}
+}
+
+// StringValue returns the contents of a UTF8String, PrintableString, IA5String or other string type as a string
+func (e Element) StringValue() (string, error) {
+	if e.Class != ClassUniversal || e.Constructed {
+		return "", e.error(ErrUnexpectedTag)
+	}
+	switch e.Tag {
+	case TagUTF8String, TagPrintableString, TagIA5String, 18, 20, 21, 25, 26, 27:
+		return string(e.Value), nil
+	}
+	return "", e.error(ErrUnexpectedTag)
+}
+
+// OID is an OBJECT IDENTIFIER
+type OID []uint64
+
+func (o OID) String() string {
+	s := make([]string, len(o))
+	for i, v := range o {
+		s[i] = strconv.FormatUint(v, 10)
+	}
+	return strings.Join(s, ".")
+}
+
+// Equal returns true if the OIDs are the same
+func (o OID) Equal(other OID) bool {
+	if len(o) != len(other) {
+		return false
+	}
+	for i := range o {
+		if o[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// OID returns the value of an OBJECT IDENTIFIER
+func (e Element) OID() (OID, error) {
+	if err := e.expect(TagOID); err != nil {
+		return nil, err
+	}
+	if len(e.Value) == 0 {
+		return nil, e.error(ErrMalformed)
+	}
+	var v OID
+	p := decoder.New(e.Value)
+	for p.RemainingLength() > 0 {
+		var n uint64
+		for i := 0; ; i++ {
+			c := p.Byte()
+			if p.Err != nil || (i == 0 && c == 0x80) || i >= 9 {
+				return nil, e.error(ErrMalformed)
+			}
+			n = n<<7 | uint64(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		if v == nil { // The first value holds the first two arcs
+			switch {
+			case n < 40:
+				v = OID{0, n}
+			case n < 80:
+				v = OID{1, n - 40}
+			default:
+				v = OID{2, n - 80}
+			}
+			continue
+		}
+		v = append(v, n)
+	}
+	return v, nil
+}
+
+// Time returns the value of a UTCTime or GeneralizedTime. DER requires UTC ("Z") with seconds
+// and no trailing zeros in fractions
+func (e Element) Time() (time.Time, error) {
+	if e.Class != ClassUniversal || (e.Tag != TagUTCTime && e.Tag != TagGeneralizedTime) || e.Constructed {
+		return time.Time{}, e.error(ErrUnexpectedTag)
+	}
+	s := string(e.Value)
+	var layouts []string
+	if e.Tag == TagUTCTime {
+		layouts = []string{"0601021504Z0700", "060102150405Z0700"}
+		if e.der {
+			layouts = []string{"060102150405Z"}
+		}
+	} else {
+		layouts = []string{"20060102150405.999999999Z0700", "20060102150405.999999999"}
+		if e.der {
+			layouts = []string{"20060102150405.999999999Z"}
+		}
+	}
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if e.der && strings.HasSuffix(strings.TrimSuffix(s, "Z"), "0") && strings.Contains(s, ".") {
+			return time.Time{}, e.error(ErrNotDER)
+		}
+		if e.Tag == TagUTCTime && t.Year() >= 2050 { // RFC 5280 two digit years
+			t = t.AddDate(-100, 0, 0)
+		}
+		return t, nil
+	}
+	return time.Time{}, e.error(fmt.Errorf("%w: invalid time %q", ErrMalformed, s))
+}
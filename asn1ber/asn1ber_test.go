@@ -0,0 +1,292 @@
+package asn1ber
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestReader(t *testing.T) {
+	// SNMP style message: SEQUENCE { INTEGER 1, OCTET STRING "public", [0] { OID 1.3.6.1.2.1.1.1.0, NULL }, BOOLEAN true }
+	msg := mustHex("" +
+		"301c" +
+		"020101" +
+		"0406" + "7075626c6963" +
+		"a00c" + "06082b06010201010100" + "0500" + "0101ff")
+	r := NewReader(decoder.New(msg))
+	seq, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !seq.Is(ClassUniversal, TagSequence) || !seq.Constructed || seq.Offset != 0 || len(seq.Raw) != len(msg) {
+		t.Fatalf("unexpected element %+v", seq)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+
+	children, err := seq.Children().All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 4 {
+		t.Fatalf("expected 4 children got %d", len(children))
+	}
+	if v, err := children[0].Int64(); err != nil || v != 1 {
+		t.Errorf("expected 1 got %d err %v", v, err)
+	}
+	if v, err := children[1].OctetString(); err != nil || string(v) != "public" {
+		t.Errorf("expected public got %q err %v", v, err)
+	}
+	ctx := children[2]
+	if !ctx.Is(ClassContextSpecific, 0) || !ctx.Constructed {
+		t.Errorf("unexpected element %+v", ctx)
+	}
+	inner, err := ctx.Children().All()
+	if err != nil || len(inner) != 2 {
+		t.Fatalf("unexpected children %v err %v", inner, err)
+	}
+	if v, err := inner[0].OID(); err != nil || v.String() != "1.3.6.1.2.1.1.1.0" {
+		t.Errorf("expected 1.3.6.1.2.1.1.1.0 got %v err %v", v, err)
+	}
+	if err := inner[1].Null(); err != nil {
+		t.Error(err)
+	}
+	if v, err := children[3].Bool(); err != nil || !v {
+		t.Errorf("expected true got %v err %v", v, err)
+	}
+	if _, err := children[3].Int64(); !errors.Is(err, ErrUnexpectedTag) {
+		t.Errorf("expected ErrUnexpectedTag got %v", err)
+	}
+}
+
+func TestIntegers(t *testing.T) {
+	tests := []struct {
+		hex string
+		v   int64
+	}{
+		{"020100", 0},
+		{"02017f", 127},
+		{"02020080", 128},
+		{"020180", -128},
+		{"0202ff7f", -129},
+		{"02087fffffffffffffff", 9223372036854775807},
+	}
+	for _, test := range tests {
+		e, err := NewReader(decoder.New(mustHex(test.hex))).Next()
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if v, err := e.Int64(); err != nil || v != test.v {
+			t.Errorf("%s: expected %d got %d err %v", test.hex, test.v, v, err)
+		}
+		if v, err := e.BigInt(); err != nil || v.Int64() != test.v {
+			t.Errorf("%s: expected big %d got %v err %v", test.hex, test.v, v, err)
+		}
+	}
+
+	e, _ := NewReader(decoder.New(mustHex("0209ff0000000000000000"))).Next()
+	if _, err := e.Int64(); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed got %v", err)
+	}
+	want, _ := new(big.Int).SetString("-18446744073709551616", 10)
+	if v, err := e.BigInt(); err != nil || v.Cmp(want) != 0 {
+		t.Errorf("expected %v got %v err %v", want, v, err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	// Application class, high tag number 1000, primitive
+	e, err := NewReader(decoder.New(mustHex("5f876801aa"))).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Is(ClassApplication, 1000) || e.Constructed || hex.EncodeToString(e.Value) != "aa" {
+		t.Errorf("unexpected element %+v", e)
+	}
+	// Long form length
+	b := append(mustHex("048200c8"), make([]byte, 200)...)
+	e, err = NewReader(decoder.New(b)).Next()
+	if err != nil || len(e.Value) != 200 {
+		t.Errorf("unexpected element %+v err %v", e, err)
+	}
+}
+
+func TestIndefinite(t *testing.T) {
+	// SEQUENCE (indefinite) { constructed OCTET STRING (indefinite) { "ab", "c" }, INTEGER 5 }
+	msg := mustHex("3080" + "2480" + "04026162" + "040163" + "0000" + "020105" + "0000" + "0500")
+	r := NewReader(decoder.New(msg))
+	seq, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !seq.Indefinite || len(seq.Raw) != len(msg)-2 {
+		t.Errorf("unexpected element %+v", seq)
+	}
+	children, err := seq.Children().All()
+	if err != nil || len(children) != 2 {
+		t.Fatalf("unexpected children %v err %v", children, err)
+	}
+	if v, err := children[0].OctetString(); err != nil || string(v) != "abc" {
+		t.Errorf("expected abc got %q err %v", v, err)
+	}
+	if v, err := children[1].Int64(); err != nil || v != 5 {
+		t.Errorf("expected 5 got %d err %v", v, err)
+	}
+	if e, err := r.Next(); err != nil || e.Null() != nil {
+		t.Errorf("unexpected element %+v err %v", e, err)
+	}
+
+	r = NewReader(decoder.New(msg))
+	r.DER = true
+	if _, err := r.Next(); !errors.Is(err, ErrNotDER) {
+		t.Errorf("expected ErrNotDER got %v", err)
+	}
+
+	r = NewReader(decoder.New(mustHex("30803080308000000000")))
+	r.MaxDepth = 2
+	if _, err := r.Next(); !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("expected ErrMaxDepth got %v", err)
+	}
+}
+
+func TestDER(t *testing.T) {
+	tests := []struct {
+		hex  string
+		read func(Element) error
+	}{
+		{"04810100", nil},   // Non-minimal long form length
+		{"0482000100", nil}, // Leading zero length byte
+		{"1f1e00", nil},     // High tag form for a low tag number
+		{"02020001", func(e Element) error { _, err := e.Int64(); return err }},
+		{"0202ff80", func(e Element) error { _, err := e.Int64(); return err }},
+		{"010101", func(e Element) error { _, err := e.Bool(); return err }},
+		{"170d3931303530363233343534305a", nil}, // Valid
+	}
+	for i, test := range tests {
+		r := NewReader(decoder.New(mustHex(test.hex)))
+		r.DER = true
+		e, err := r.Next()
+		if err == nil && test.read != nil {
+			err = test.read(e)
+		}
+		if i == len(tests)-1 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.hex, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrNotDER) {
+			t.Errorf("%s: expected ErrNotDER got %v", test.hex, err)
+		}
+		// The same data is valid BER
+		e, err = NewReader(decoder.New(mustHex(test.hex))).Next()
+		if err == nil && test.read != nil {
+			err = test.read(e)
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected BER error %v", test.hex, err)
+		}
+	}
+}
+
+func TestReaderKeepsByteOrder(t *testing.T) {
+	p := decoder.New(mustHex("020201020304"))
+	p.SetLittleEndian()
+	e, err := NewReader(p).Next()
+	if v, _ := e.Int64(); err != nil || v != 0x0102 {
+		t.Errorf("expected 0x0102 got %d err %v", v, err)
+	}
+	if v := p.Uint16(); v != 0x0403 {
+		t.Errorf("expected the packet to still be little endian got 0x%04X", v)
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string
+		err  error
+	}{
+		{"0c03616263", "abc", nil},
+		{"1302414243", "AB", nil},
+		{"16026869", "hi", nil},
+		{"04026869", "", ErrUnexpectedTag},
+	}
+	for _, test := range tests {
+		e, err := NewReader(decoder.New(mustHex(test.hex))).Next()
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if v, err := e.StringValue(); !errors.Is(err, test.err) || v != test.want {
+			t.Errorf("%s: expected %q got %q err %v", test.hex, test.want, v, err)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want time.Time
+	}{
+		{"170d3931303530363233343534305a", time.Date(1991, 5, 6, 23, 45, 40, 0, time.UTC)},
+		{"170d3439313233313233353935395a", time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{"180f32303230303130323033303430355a", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"181332303230303130323033303430352e3132355a", time.Date(2020, 1, 2, 3, 4, 5, 125000000, time.UTC)},
+	}
+	for _, test := range tests {
+		e, err := NewReader(decoder.New(mustHex(test.hex))).Next()
+		if err != nil {
+			t.Errorf("%s: %v", test.hex, err)
+			continue
+		}
+		if v, err := e.Time(); err != nil || !v.Equal(test.want) {
+			t.Errorf("%s: expected %v got %v err %v", test.hex, test.want, v, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		hex    string
+		err    error
+		offset int
+	}{
+		{"0405616263", decoder.ErrReadPastEndData, 1},
+		{"30", decoder.ErrReadPastEndData, 0},
+		{"0480", ErrMalformed, 1},
+		{"0000", ErrMalformed, 0},
+		{"3080020101", decoder.ErrReadPastEndData, 0},
+		{"30030405", decoder.ErrReadPastEndData, 1},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		_, err := NewReader(p).Next()
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) {
+			t.Errorf("%s: expected %v got %v", test.hex, test.err, err)
+			continue
+		}
+		if oe.Offset != test.offset {
+			t.Errorf("%s: expected offset %d got %d", test.hex, test.offset, oe.Offset)
+		}
+		if p.Index() != 0 || p.Err != nil {
+			t.Errorf("%s: expected packet restored got index %d err %v", test.hex, p.Index(), p.Err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package decoder
+
+// The At functions read a value at an absolute offset without moving the internal pointer.
+// Errors are set in Err as an *OffsetError holding the offset of the value
+
+// ByteAt returns the byte at the given offset
+func (p *Packet) ByteAt(offset int) byte {
+	q := p.cursorAt(offset)
+	v := q.Byte()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Uint16At returns the uint16 at the given offset
+func (p *Packet) Uint16At(offset int) uint16 {
+	q := p.cursorAt(offset)
+	v := q.Uint16()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Uint24At returns the 24 bit value at the given offset
+func (p *Packet) Uint24At(offset int) uint32 {
+	q := p.cursorAt(offset)
+	v := q.Uint24()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Uint32At returns the uint32 at the given offset
+func (p *Packet) Uint32At(offset int) uint32 {
+	q := p.cursorAt(offset)
+	v := q.Uint32()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Uint64At returns the uint64 at the given offset
+func (p *Packet) Uint64At(offset int) uint64 {
+	q := p.cursorAt(offset)
+	v := q.Uint64()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Float32At returns the float32 at the given offset
+func (p *Packet) Float32At(offset int) float32 {
+	q := p.cursorAt(offset)
+	v := q.Float32()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// Float64At returns the float64 at the given offset
+func (p *Packet) Float64At(offset int) float64 {
+	q := p.cursorAt(offset)
+	v := q.Float64()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// BytesAt returns the given number of bytes at the given offset, the slice aliases the packet's buffer
+func (p *Packet) BytesAt(offset int, length int) []byte {
+	q := p.cursorAt(offset)
+	v := q.Bytes(length)
+	p.atError(offset, q.Err)
+	return v
+}
+
+// CStringAt returns the NULL terminated string at the given offset
+func (p *Packet) CStringAt(offset int) string {
+	q := p.cursorAt(offset)
+	v := q.CString()
+	p.atError(offset, q.Err)
+	return v
+}
+
+// StringZeroPaddedAt returns the null padded string at the given offset
+func (p *Packet) StringZeroPaddedAt(offset int, fixedLength int) string {
+	q := p.cursorAt(offset)
+	v := q.StringZeroPadded(fixedLength)
+	p.atError(offset, q.Err)
+	return v
+}
+
+func (p *Packet) atError(offset int, err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := err.(*OffsetError); ok {
+		p.Err = err
+		return
+	}
+	p.Err = &OffsetError{Offset: offset, Err: err}
+}
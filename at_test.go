@@ -0,0 +1,75 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	// A header holding the offset of a string and a uint32
+	p := New([]byte{0x06, 0x09, 0xff, 0xff, 0xff, 0xff, 'A', 'B', 0x00, 0xde, 0xad, 0xbe, 0xef})
+	p.Byte()
+
+	if s := p.CStringAt(int(p.ByteAt(0))); s != "AB" {
+		t.Errorf("expected 'AB' got '%s'", s)
+	}
+	if v := p.Uint32At(int(p.ByteAt(1))); v != 0xdeadbeef {
+		t.Errorf("expected 0xdeadbeef got %X", v)
+	}
+	if v := p.Uint16At(11); v != 0xbeef {
+		t.Errorf("expected 0xbeef got %X", v)
+	}
+	if b := p.BytesAt(6, 2); string(b) != "AB" {
+		t.Errorf("expected 'AB' got '%s'", b)
+	}
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+	if p.Index() != 1 {
+		t.Errorf("expected index 1 got %d", p.Index())
+	}
+
+	tests := []struct {
+		read   func()
+		offset int
+	}{
+		{func() { p.Uint32At(10) }, 10},
+		{func() { p.Uint16At(-1) }, -1},
+		{func() { p.ByteAt(13) }, 13},
+		{func() { p.BytesAt(12, 2) }, 12},
+		{func() { p.CStringAt(9) }, 9},
+	}
+	for _, test := range tests {
+		p.Err = nil
+		test.read()
+		var e *OffsetError
+		if !errors.As(p.Err, &e) || e.Offset != test.offset || e.Err != ErrReadPastEndData {
+			t.Errorf("expected ErrReadPastEndData at %d got %v", test.offset, p.Err)
+		}
+	}
+}
+
+func TestSeekTo(t *testing.T) {
+	p := New(data1)
+	if err := p.SeekTo(4); err != nil || p.Byte() != 0x04 {
+		t.Errorf("expected to read 0x04 at 4 err %v", err)
+	}
+	if err := p.SeekTo(len(data1)); err != nil || !p.EOF() {
+		t.Errorf("expected to seek to EOF got err %v", err)
+	}
+	if err := p.SeekTo(len(data1) + 1); !errors.Is(err, ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+	if err := p.Rewind(1); err != nil || p.Byte() != 0x05 {
+		t.Errorf("expected to rewind to the last byte err %v", err)
+	}
+	if err := p.Rewind(0); err != nil || !p.EOF() {
+		t.Errorf("expected Rewind(0) at EOF to succeed got err %v", err)
+	}
+	if err := p.Rewind(-1); err != ErrReadPastEndData || !p.EOF() {
+		t.Errorf("expected ErrReadPastEndData past EOF got %v", err)
+	}
+	if err := p.Rewind(len(data1) + 1); err != ErrReadPastEndData || !p.EOF() {
+		t.Errorf("expected ErrReadPastEndData before the start got %v", err)
+	}
+}
@@ -13,3 +13,75 @@ func (p *Packet) Bits8() []bool {
 	}
 	return v
 }
+
+// The bit reader keeps an absolute bit position alongside the byte pointer. Bits reads MSB-first, where bit
+// position k is bit 7-k%8 of byte k/8, and BitsLSB reads LSB-first, where it is bit k%8 of byte k/8.
+// Byte reads start at the byte holding the bit position, use AlignByte first to skip a partly read byte
+
+// BitIndex returns the absolute bit position
+func (p *Packet) BitIndex() int {
+	if p.bit>>3 == p.idx {
+		return p.bit
+	}
+	// A byte read has moved the pointer on since the last bit read, any other move resets the bit position
+	return p.idx * 8
+}
+
+// SeekBit moves to the given absolute bit position, the end of the data being valid
+func (p *Packet) SeekBit(pos int) error {
+	if pos < 0 || pos > p.length*8 {
+		return &OffsetError{Offset: pos >> 3, Err: ErrReadPastEndData}
+	}
+	p.idx = pos >> 3
+	p.bit = pos
+	return nil
+}
+
+// AlignByte moves the bit position on to the start of the next byte, unless it is already at the start of one
+func (p *Packet) AlignByte() {
+	if pos := p.BitIndex(); pos&7 != 0 {
+		p.SeekBit((pos + 7) &^ 7)
+	}
+}
+
+// Bits returns the next n (up to 64) bits read MSB-first, the first bit read being the most significant
+func (p *Packet) Bits(n int) uint64 {
+	pos, ok := p.bitRange(n)
+	if !ok {
+		return 0
+	}
+	var v uint64
+	for i := pos; i < pos+n; i++ {
+		v = v<<1 | uint64(p.buf[i>>3]>>(7-uint(i&7))&1)
+	}
+	p.SeekBit(pos + n)
+	return v
+}
+
+// BitsLSB returns the next n (up to 64) bits read LSB-first, the first bit read being the least significant
+func (p *Packet) BitsLSB(n int) uint64 {
+	pos, ok := p.bitRange(n)
+	if !ok {
+		return 0
+	}
+	var v uint64
+	for i := 0; i < n; i++ {
+		v |= uint64(p.buf[(pos+i)>>3]>>uint((pos+i)&7)&1) << uint(i)
+	}
+	p.SeekBit(pos + n)
+	return v
+}
+
+// bitRange returns the bit position, setting Err if n bits can not be read from it
+func (p *Packet) bitRange(n int) (int, bool) {
+	if n < 0 || n > 64 {
+		p.Err = ErrReadInvalidLength
+		return 0, false
+	}
+	pos := p.BitIndex()
+	if pos+n > p.length*8 {
+		p.Err = ErrReadPastEndData
+		return 0, false
+	}
+	return pos, true
+}
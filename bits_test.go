@@ -0,0 +1,115 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBits(t *testing.T) {
+	p := New([]byte{0xA5, 0x3C})
+	if v := p.Bits(4); v != 0xA {
+		t.Errorf("expected 0xA got 0x%X", v)
+	}
+	if v := p.Bits(8); v != 0x53 {
+		t.Errorf("expected 0x53 got 0x%X", v)
+	}
+	if p.BitIndex() != 12 || p.Index() != 1 {
+		t.Errorf("expected bit 12 & index 1 got %d & %d", p.BitIndex(), p.Index())
+	}
+	p.AlignByte()
+	if p.BitIndex() != 16 || !p.EOF() || p.Err != nil {
+		t.Errorf("expected bit 16 at EOF got %d err %v", p.BitIndex(), p.Err)
+	}
+
+	p.Reset()
+	if v := p.BitsLSB(4); v != 0x5 {
+		t.Errorf("expected 0x5 got 0x%X", v)
+	}
+	if v := p.BitsLSB(8); v != 0xCA {
+		t.Errorf("expected 0xCA got 0x%X", v)
+	}
+
+	// Byte reads start at the byte holding the bit position
+	p.SeekTo(0)
+	p.Bits(3)
+	if v := p.Byte(); v != 0xA5 || p.BitIndex() != 8 {
+		t.Errorf("expected 0xA5 at bit 8 got 0x%X at %d", v, p.BitIndex())
+	}
+	if v := p.Bits(16); v != 0 || !errors.Is(p.Err, ErrReadPastEndData) || p.BitIndex() != 8 {
+		t.Errorf("expected ErrReadPastEndData at bit 8 got %v at %d", p.Err, p.BitIndex())
+	}
+	p.Err = nil
+	p.Bits(65)
+	if !errors.Is(p.Err, ErrReadInvalidLength) {
+		t.Errorf("expected ErrReadInvalidLength got %v", p.Err)
+	}
+}
+
+func TestBitsLong(t *testing.T) {
+	b := []byte{0x81, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0xFF}
+	p := New(b)
+	p.SeekBit(4)
+	if v := p.Bits(64); v != 0x102030405060708F {
+		t.Errorf("expected 0x102030405060708F got 0x%X", v)
+	}
+	p.SeekBit(0)
+	if v := p.BitsLSB(64); v != 0x0807060504030281 {
+		t.Errorf("expected 0x0807060504030281 got 0x%X", v)
+	}
+	if err := p.SeekBit(73); err == nil {
+		t.Error("expected error seeking past end")
+	}
+}
+
+func TestBitsMark(t *testing.T) {
+	p := New([]byte{0xF0, 0x0F})
+	p.Bits(5)
+	m := p.Mark()
+	p.Bits(7)
+	p.Restore(m)
+	if p.BitIndex() != 5 {
+		t.Errorf("expected bit 5 got %d", p.BitIndex())
+	}
+	if v := p.Bits(3); v != 0 {
+		t.Errorf("expected 0 got %d", v)
+	}
+	p.Load([]byte{0x80})
+	if p.BitIndex() != 0 || p.Bits(1) != 1 {
+		t.Error("expected Load to reset the bit position")
+	}
+}
+
+func TestBitsAfterSeek(t *testing.T) {
+	tests := []struct {
+		name string
+		move func(p *Packet)
+	}{
+		{"Seek", func(p *Packet) { p.Seek([]byte{0x3C}) }},
+		{"SeekByte", func(p *Packet) { p.SeekByte(0x3C) }},
+		{"SeekBack", func(p *Packet) { p.Byte(); p.SeekBack([]byte{0x3C}) }},
+		{"SeekAny", func(p *Packet) { p.SeekAny([]byte{0x3C}) }},
+		{"Rewind", func(p *Packet) { p.Byte(); p.Rewind(1) }},
+	}
+	for _, test := range tests {
+		p := New([]byte{0xA5, 0x3C, 0xFF})
+		p.Byte()
+		p.Bits(3)
+		test.move(p)
+		if p.Index() != 1 {
+			t.Errorf("%s: expected index 1 got %d", test.name, p.Index())
+			continue
+		}
+		if v := p.BitIndex(); v != p.Index()*8 {
+			t.Errorf("%s: expected bit index %d got %d", test.name, p.Index()*8, v)
+		}
+	}
+
+	// Bits read after seeking back start at bit 0 of the byte
+	p := New([]byte{0xA5, 0x3C})
+	p.Byte()
+	p.Bits(3)
+	p.Seek([]byte{0x3C})
+	if v := p.Bits(4); v != 0x3 {
+		t.Errorf("expected 0x3 got 0x%X", v)
+	}
+}
@@ -0,0 +1,64 @@
+package decoder
+
+import "encoding/binary"
+
+// Buffer holds data that never changes, so it can be shared between goroutines that each
+// read it through their own Cursor
+type Buffer struct {
+	buf []byte
+}
+
+// Cursor reads a Buffer, holding its own position, error, endian and other settings.
+// Cursors are Packets so have all the same read functions; they are cheap to create and
+// any number may read the same Buffer concurrently, but a single Cursor must not be shared
+type Cursor = Packet
+
+// NewBuffer returns a Buffer holding a copy of the given data
+func NewBuffer(b []byte) *Buffer {
+	return &Buffer{
+		buf: copyBytes(b),
+	}
+}
+
+// Len returns the length of the data
+func (b *Buffer) Len() int {
+	return len(b.buf)
+}
+
+// Cursor returns a new Cursor at the start of the data
+func (b *Buffer) Cursor() *Cursor {
+	return &Cursor{
+		buf:    b.buf,
+		length: len(b.buf),
+		endian: binary.BigEndian,
+	}
+}
+
+// CursorAt returns a new Cursor at the given offset, if the offset is outside of the data
+// the Cursor's Err is set to an *OffsetError
+func (b *Buffer) CursorAt(offset int) *Cursor {
+	c := b.Cursor()
+	if err := c.SeekTo(offset); err != nil {
+		c.idx = c.length
+		c.Err = err
+	}
+	return c
+}
+
+// Section returns a new Cursor limited to the given region of the data, reading past the end
+// of the region gives ErrReadPastEndData as if it were the whole packet
+func (b *Buffer) Section(offset int, length int) *Cursor {
+	if offset < 0 || length < 0 || offset+length > len(b.buf) {
+		c := b.Cursor()
+		c.idx = c.length
+		c.Err = &OffsetError{Offset: offset, Err: ErrReadPastEndData}
+		return c
+	}
+	// Limit the capacity so the section can't be extended into the rest of the buffer
+	s := b.buf[offset : offset+length : offset+length]
+	return &Cursor{
+		buf:    s,
+		length: len(s),
+		endian: binary.BigEndian,
+	}
+}
@@ -0,0 +1,79 @@
+package decoder
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	data := []byte{0x00, 0x01, 0x02, 0x03, 'A', 0x00}
+	b := NewBuffer(data)
+	data[0] = 0xff // The buffer holds a copy
+
+	c1 := b.Cursor()
+	c2 := b.CursorAt(2)
+	if v := c1.Uint16(); v != 0x0001 {
+		t.Errorf("expected 0x0001 got %X", v)
+	}
+	c2.SetLittleEndian()
+	if v := c2.Uint16(); v != 0x0302 {
+		t.Errorf("expected 0x0302 got %X", v)
+	}
+	if v := c1.Uint16(); v != 0x0203 {
+		t.Errorf("expected cursors to be independent, got %X", v)
+	}
+
+	s := b.Section(4, 2)
+	if v := s.CString(); v != "A" || s.Err != nil {
+		t.Errorf("expected 'A' got '%s' err %v", v, s.Err)
+	}
+	s = b.Section(2, 2)
+	s.Uint32()
+	if s.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", s.Err)
+	}
+
+	if c := b.CursorAt(7); !errors.Is(c.Err, ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", c.Err)
+	}
+	if c := b.Section(4, 3); !errors.Is(c.Err, ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", c.Err)
+	}
+}
+
+// Run with -race to check cursors can share a buffer
+func TestBufferConcurrent(t *testing.T) {
+	data := make([]byte, 4096)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	b := NewBuffer(data)
+	in := NewInterner(0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			c := b.Section(g*512, 512)
+			c.SetInterner(in)
+			if g%2 == 1 {
+				c.SetLittleEndian()
+			}
+			for i := 0; i < 100; i++ {
+				c.Reset()
+				for !c.EOF() {
+					c.Uint16()
+					c.PeekByte()
+					c.StringZeroPadded(2)
+				}
+				if c.Err != nil {
+					t.Errorf("goroutine %d got unexpected err: %s", g, c.Err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -1,6 +1,7 @@
 package decoder
 
-// Bytes returns the given number of bytes at the internal pointer and increments it accordingly
+// Bytes returns the given number of bytes at the internal pointer and increments it accordingly.
+// The returned slice aliases the buffer passed to New, use BytesCopy to keep the bytes after the buffer is reused
 func (p *Packet) Bytes(length int) []byte {
 	if length < 1 {
 		return []byte{} // Ask for nothing... you get nothing
@@ -14,6 +15,6 @@ func (p *Packet) Bytes(length int) []byte {
 		newIdx = len(p.buf) - 1
 	}
 	b := p.buf[p.idx:newIdx]
-	p.idx = newIdx
+	p.setIndex(newIdx)
 	return b
 }
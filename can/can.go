@@ -0,0 +1,142 @@
+// Package can decodes SocketCAN can_frame and canfd_frame structures using a *decoder.Packet, and extracts
+// signals from their data as defined by a DBC file.
+package can
+
+import (
+	"errors"
+	"io"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrInvalidLength = errors.New("invalid data length")
+var ErrInvalidFrameSize = errors.New("invalid frame size")
+
+// SocketCAN can_id flags & masks
+const (
+	EFFFlag = 0x80000000 // Extended frame format, 29 bit identifier
+	RTRFlag = 0x40000000 // Remote transmission request
+	ERRFlag = 0x20000000 // Error message frame
+	SFFMask = 0x000007ff
+	EFFMask = 0x1fffffff
+)
+
+// SocketCAN CAN FD flags
+const (
+	FDFlagBRS = 0x01 // Bit rate switch
+	FDFlagESI = 0x02 // Error state indicator
+	FDFlagFDF = 0x04 // FD frame, set on frames received from the kernel
+)
+
+// Frame sizes
+const (
+	FrameSize   = 16 // sizeof(struct can_frame)
+	FDFrameSize = 72 // sizeof(struct canfd_frame)
+)
+
+// Frame is a classic CAN or CAN FD frame
+type Frame struct {
+	ID       uint32 // The 11 or 29 bit identifier
+	Extended bool
+	Remote   bool
+	Error    bool
+	FD       bool
+	Flags    uint8  // CAN FD flags
+	DLC      uint8  // The raw DLC of a classic frame, which is 9 to 15 for 8 data bytes if set by the sender
+	Data     []byte // The data, which aliases the packet's buffer
+	Offset   int    // The offset of the frame in the packet
+}
+
+// Reader reads consecutive frames
+type Reader struct {
+	p *decoder.Packet
+}
+
+// NewReader returns a Reader of the frames from the packet's current position. SocketCAN uses the host's byte
+// order, the packet is set to little endian as used by x86 & ARM hosts, call SetBigEndian on it afterwards
+// for data from a big endian host
+func NewReader(p *decoder.Packet) *Reader {
+	p.SetLittleEndian()
+	return &Reader{p: p}
+}
+
+// Next returns the next classic can_frame, or io.EOF at the end of the data
+func (r *Reader) Next() (Frame, error) {
+	return r.next(false)
+}
+
+// NextFD returns the next canfd_frame, or io.EOF at the end of the data
+func (r *Reader) NextFD() (Frame, error) {
+	return r.next(true)
+}
+
+func (r *Reader) next(fd bool) (Frame, error) {
+	p := r.p
+	if p.RemainingLength() == 0 {
+		return Frame{}, io.EOF
+	}
+	f := Frame{Offset: p.Index(), FD: fd}
+	size := FrameSize
+	if fd {
+		size = FDFrameSize
+	}
+	if p.RemainingLength() < size {
+		return Frame{}, &decoder.OffsetError{Offset: f.Offset, Err: decoder.ErrReadPastEndData}
+	}
+
+	id := p.Uint32()
+	f.Extended = id&EFFFlag != 0
+	f.Remote = id&RTRFlag != 0
+	f.Error = id&ERRFlag != 0
+	if f.Extended {
+		f.ID = id & EFFMask
+	} else {
+		f.ID = id & SFFMask
+	}
+	length := int(p.Byte())
+	if fd {
+		f.Flags = p.Byte()
+		p.Bytes(2) // res0 & res1
+		if !ValidFDLength(length) {
+			p.SeekTo(f.Offset)
+			return Frame{}, &decoder.OffsetError{Offset: f.Offset + 4, Err: ErrInvalidLength}
+		}
+		f.Data = p.Bytes(64)[:length]
+		return f, nil
+	}
+
+	p.Bytes(2) // __pad & __res0
+	len8DLC := p.Byte()
+	if length > 8 {
+		p.SeekTo(f.Offset)
+		return Frame{}, &decoder.OffsetError{Offset: f.Offset + 4, Err: ErrInvalidLength}
+	}
+	f.DLC = uint8(length)
+	if length == 8 && len8DLC > 8 && len8DLC <= 15 {
+		f.DLC = len8DLC
+	}
+	f.Data = p.Bytes(8)[:length]
+	return f, nil
+}
+
+// Decode returns the frame in b as read from a SocketCAN raw socket, which is a can_frame or canfd_frame
+// depending on its size
+func Decode(b []byte) (Frame, error) {
+	r := NewReader(decoder.New(b))
+	switch len(b) {
+	case FrameSize:
+		return r.Next()
+	case FDFrameSize:
+		return r.NextFD()
+	}
+	return Frame{}, &decoder.OffsetError{Offset: 0, Err: ErrInvalidFrameSize}
+}
+
+// ValidFDLength returns true if n is a data length a CAN FD frame can have
+func ValidFDLength(n int) bool {
+	switch n {
+	case 12, 16, 20, 24, 32, 48, 64:
+		return true
+	}
+	return n >= 0 && n <= 8
+}
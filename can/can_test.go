@@ -0,0 +1,91 @@
+package can
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestReader(t *testing.T) {
+	b := mustHex("" +
+		"00f1fe98" + "02000000" + "01fb000000000000" + // Extended 0x18FEF100, 2 bytes
+		"64000000" + "0800000f" + "0102030405060708" + // Standard 0x64, 8 bytes with DLC 15
+		"23010040" + "00000000" + "0000000000000000") // Remote 0x123
+	r := NewReader(decoder.New(b))
+	frames := []Frame{}
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames got %d", len(frames))
+	}
+	if f := frames[0]; f.ID != 0x18FEF100 || !f.Extended || f.Remote || hex.EncodeToString(f.Data) != "01fb" || f.DLC != 2 {
+		t.Errorf("unexpected frame %+v", f)
+	}
+	if f := frames[1]; f.ID != 0x64 || f.Extended || len(f.Data) != 8 || f.DLC != 15 || f.Offset != 16 {
+		t.Errorf("unexpected frame %+v", f)
+	}
+	if f := frames[2]; f.ID != 0x123 || !f.Remote || len(f.Data) != 0 {
+		t.Errorf("unexpected frame %+v", f)
+	}
+}
+
+func TestDecodeFD(t *testing.T) {
+	b := make([]byte, FDFrameSize)
+	copy(b, mustHex("23010000"+"0c050000"+"000102030405060708090a0b"))
+	f, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.ID != 0x123 || !f.FD || f.Flags != FDFlagBRS|FDFlagFDF || hex.EncodeToString(f.Data) != "000102030405060708090a0b" {
+		t.Errorf("unexpected frame %+v", f)
+	}
+
+	b[4] = 13
+	if _, err := Decode(b); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("expected ErrInvalidLength got %v", err)
+	}
+	if _, err := Decode(b[:20]); !errors.Is(err, ErrInvalidFrameSize) {
+		t.Errorf("expected ErrInvalidFrameSize got %v", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		hex    string
+		err    error
+		offset int
+	}{
+		{"64000000" + "09000000" + "0102030405060708", ErrInvalidLength, 4},
+		{"64000000" + "08000000" + "01020304050607", decoder.ErrReadPastEndData, 0},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		_, err := NewReader(p).Next()
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("expected %v at offset %d got %v", test.err, test.offset, err)
+		}
+		if p.Index() != 0 {
+			t.Errorf("expected packet restored got index %d", p.Index())
+		}
+	}
+}
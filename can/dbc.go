@@ -0,0 +1,345 @@
+package can
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformedDBC = errors.New("malformed DBC")
+var ErrUnknownMessage = errors.New("unknown message")
+var ErrOutOfRange = errors.New("value out of range")
+
+// LineError records the line of a DBC file at which an error occurred
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s on line %d", e.Err, e.Line)
+}
+
+// Unwrap returns the underlying error for use with errors.Is
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Database is the messages defined by a DBC file
+type Database struct {
+	Messages []*Message // In the order they are defined
+	byID     map[uint32]*Message
+}
+
+// Message is a BO_ definition
+type Message struct {
+	ID       uint32 // The 11 or 29 bit identifier
+	Extended bool
+	Name     string
+	Length   int
+	Sender   string
+	Signals  []*Signal
+}
+
+// Signal is an SG_ definition
+type Signal struct {
+	Name           string
+	StartBit       int  // The LSB for Intel, or the MSB for Motorola, in DBC bit numbering
+	Length         int  // In bits
+	LittleEndian   bool // Intel (@1) byte order, otherwise Motorola (@0)
+	Signed         bool
+	Factor         float64
+	Offset         float64
+	Min            float64
+	Max            float64 // Min & Max are both 0 when the range is unlimited
+	Unit           string
+	Receivers      []string
+	Multiplexor    bool // The signal selects which multiplexed signals are present
+	MultiplexValue int  // The multiplexor value the signal is present for, or -1 if it is always present
+}
+
+// ParseDBC reads the message & signal definitions of a DBC file, other sections are ignored.
+// Errors are a *LineError
+func ParseDBC(r io.Reader) (*Database, error) {
+	db := &Database{byID: make(map[uint32]*Message)}
+	lr := decoder.NewLineReader(r, decoder.DefaultLineConfig)
+	var msg *Message
+	for n := 1; ; n++ {
+		line, err := lr.Line()
+		if err == io.EOF {
+			return db, nil
+		}
+		if err != nil {
+			return nil, &LineError{Line: n, Err: err}
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "BO_ "):
+			msg, err = parseMessage(line[4:])
+			if err == nil {
+				key := msg.ID
+				if msg.Extended {
+					key |= EFFFlag
+				}
+				if db.byID[key] != nil {
+					err = fmt.Errorf("%w: duplicate message %d", ErrMalformedDBC, msg.ID)
+				}
+				db.byID[key] = msg
+				db.Messages = append(db.Messages, msg)
+			}
+		case strings.HasPrefix(line, "SG_ "):
+			if msg == nil {
+				err = fmt.Errorf("%w: signal outside of a message", ErrMalformedDBC)
+				break
+			}
+			var s *Signal
+			s, err = parseSignal(line[4:])
+			if err == nil {
+				msg.Signals = append(msg.Signals, s)
+			}
+		case line == "":
+			msg = nil
+		}
+		if err != nil {
+			return nil, &LineError{Line: n, Err: err}
+		}
+	}
+}
+
+// parseMessage parses "2364540158 EEC1: 8 Vector__XXX"
+func parseMessage(s string) (*Message, error) {
+	colon := strings.IndexByte(s, ':')
+	if colon == -1 {
+		return nil, ErrMalformedDBC
+	}
+	head := strings.Fields(s[:colon])
+	tail := strings.Fields(s[colon+1:])
+	if len(head) != 2 || len(tail) < 1 {
+		return nil, ErrMalformedDBC
+	}
+	id, err := strconv.ParseUint(head[0], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id %q", ErrMalformedDBC, head[0])
+	}
+	length, err := strconv.Atoi(tail[0])
+	if err != nil || length < 0 || length > 64 {
+		return nil, fmt.Errorf("%w: invalid length %q", ErrMalformedDBC, tail[0])
+	}
+	m := &Message{
+		ID:     uint32(id) & EFFMask,
+		Name:   head[1],
+		Length: length,
+	}
+	m.Extended = uint32(id)&EFFFlag != 0
+	if len(tail) > 1 {
+		m.Sender = tail[1]
+	}
+	return m, nil
+}
+
+// parseSignal parses `EngineSpeed m1 : 24|16@1+ (0.125,0) [0|8031.875] "rpm" Vector__XXX`
+func parseSignal(s string) (*Signal, error) {
+	colon := strings.IndexByte(s, ':')
+	if colon == -1 {
+		return nil, ErrMalformedDBC
+	}
+	head := strings.Fields(s[:colon])
+	if len(head) < 1 || len(head) > 2 {
+		return nil, ErrMalformedDBC
+	}
+	sig := &Signal{Name: head[0], MultiplexValue: -1}
+	if len(head) == 2 {
+		switch mux := head[1]; {
+		case mux == "M":
+			sig.Multiplexor = true
+		case strings.HasPrefix(mux, "m"):
+			v, err := strconv.Atoi(strings.TrimSuffix(mux[1:], "M"))
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("%w: invalid multiplex indicator %q", ErrMalformedDBC, mux)
+			}
+			sig.MultiplexValue = v
+			sig.Multiplexor = strings.HasSuffix(mux, "M")
+		default:
+			return nil, fmt.Errorf("%w: invalid multiplex indicator %q", ErrMalformedDBC, mux)
+		}
+	}
+
+	p := decoder.New([]byte(s[colon+1:]))
+	spec := strings.TrimSpace(until(p, '('))
+	factorOffset := until(p, ')')
+	until(p, '[')
+	minMax := until(p, ']')
+	until(p, '"')
+	sig.Unit = until(p, '"')
+	if p.Err != nil {
+		return nil, fmt.Errorf("%w: invalid signal %q", ErrMalformedDBC, head[0])
+	}
+	for _, r := range strings.Split(strings.TrimSpace(string(p.PeekRemainingBytes())), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			sig.Receivers = append(sig.Receivers, r)
+		}
+	}
+
+	// Start bit, length, byte order & sign e.g. "24|16@1+"
+	bar, at := strings.IndexByte(spec, '|'), strings.IndexByte(spec, '@')
+	if bar == -1 || at < bar || len(spec) != at+3 {
+		return nil, fmt.Errorf("%w: invalid signal %q", ErrMalformedDBC, spec)
+	}
+	var err1, err2 error
+	sig.StartBit, err1 = strconv.Atoi(spec[:bar])
+	sig.Length, err2 = strconv.Atoi(spec[bar+1 : at])
+	if err1 != nil || err2 != nil || sig.StartBit < 0 || sig.StartBit >= 512 || sig.Length < 1 || sig.Length > 64 {
+		return nil, fmt.Errorf("%w: invalid signal %q", ErrMalformedDBC, spec)
+	}
+	switch spec[at+1] {
+	case '0':
+	case '1':
+		sig.LittleEndian = true
+	default:
+		return nil, fmt.Errorf("%w: invalid byte order %q", ErrMalformedDBC, spec)
+	}
+	switch spec[at+2] {
+	case '+':
+	case '-':
+		sig.Signed = true
+	default:
+		return nil, fmt.Errorf("%w: invalid sign %q", ErrMalformedDBC, spec)
+	}
+
+	var err error
+	if sig.Factor, sig.Offset, err = parsePair(factorOffset, ","); err != nil {
+		return nil, err
+	}
+	if sig.Min, sig.Max, err = parsePair(minMax, "|"); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// until returns the text up to the delimiter and moves past it, setting Err if it is not found
+func until(p *decoder.Packet, delimiter byte) string {
+	b := p.StringByDelimiterView(delimiter)
+	if b == nil && p.Err == nil {
+		p.Err = decoder.ErrReadPastEndData
+	}
+	return string(b)
+}
+
+func parsePair(s string, sep string) (float64, float64, error) {
+	parts := strings.Split(s, sep)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%w: invalid pair %q", ErrMalformedDBC, s)
+	}
+	a, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	b, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("%w: invalid pair %q", ErrMalformedDBC, s)
+	}
+	return a, b, nil
+}
+
+// Message returns the message with the given identifier, or nil if there is none
+func (db *Database) Message(id uint32, extended bool) *Message {
+	if extended {
+		id |= EFFFlag
+	}
+	return db.byID[id]
+}
+
+// Decode returns the frame's message and its signals' physical values by name
+func (db *Database) Decode(f Frame) (*Message, map[string]float64, error) {
+	m := db.Message(f.ID, f.Extended)
+	if m == nil {
+		return nil, nil, ErrUnknownMessage
+	}
+	v, err := m.Decode(f.Data)
+	return m, v, err
+}
+
+// Signal returns the signal with the given name, or nil if there is none
+func (m *Message) Signal(name string) *Signal {
+	for _, s := range m.Signals {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Decode returns the physical values by name of the signals present in data. Multiplexed signals are only
+// included when the multiplexor has their value. If a value is outside of its signal's range it is included
+// and ErrOutOfRange returned once all signals are decoded
+func (m *Message) Decode(data []byte) (map[string]float64, error) {
+	mux := int64(-1)
+	for _, s := range m.Signals {
+		if s.Multiplexor && s.MultiplexValue == -1 {
+			raw, err := s.Raw(data)
+			if err != nil {
+				return nil, err
+			}
+			mux = int64(raw)
+		}
+	}
+	values := make(map[string]float64, len(m.Signals))
+	var rangeErr error
+	for _, s := range m.Signals {
+		if s.MultiplexValue != -1 && int64(s.MultiplexValue) != mux {
+			continue
+		}
+		v, err := s.Value(data)
+		if errors.Is(err, ErrOutOfRange) {
+			rangeErr = err
+		} else if err != nil {
+			return nil, err
+		}
+		values[s.Name] = v
+	}
+	return values, rangeErr
+}
+
+// Raw returns the signal's raw unsigned bits from data
+func (s *Signal) Raw(data []byte) (uint64, error) {
+	p := decoder.New(data)
+	var v uint64
+	if s.LittleEndian {
+		// Intel signals run LSB-first from the start bit
+		if err := p.SeekBit(s.StartBit); err != nil {
+			return 0, fmt.Errorf("signal %s: %w", s.Name, err)
+		}
+		v = p.BitsLSB(s.Length)
+	} else {
+		// Motorola signals run MSB-first from the start bit, bit 7 of each byte being its first
+		if err := p.SeekBit(s.StartBit/8*8 + 7 - s.StartBit%8); err != nil {
+			return 0, fmt.Errorf("signal %s: %w", s.Name, err)
+		}
+		v = p.Bits(s.Length)
+	}
+	if p.Err != nil {
+		return 0, fmt.Errorf("signal %s: %w", s.Name, p.Err)
+	}
+	return v, nil
+}
+
+// Value returns the signal's physical value from data, raw * factor + offset. If it is outside of Min & Max
+// the value is returned with ErrOutOfRange
+func (s *Signal) Value(data []byte) (float64, error) {
+	raw, err := s.Raw(data)
+	if err != nil {
+		return 0, err
+	}
+	var v float64
+	if s.Signed {
+		shift := uint(64 - s.Length)
+		v = float64(int64(raw<<shift)>>shift)*s.Factor + s.Offset
+	} else {
+		v = float64(raw)*s.Factor + s.Offset
+	}
+	if (s.Min != 0 || s.Max != 0) && (v < s.Min || v > s.Max) {
+		return v, fmt.Errorf("signal %s: %w: %v", s.Name, ErrOutOfRange, v)
+	}
+	return v, nil
+}
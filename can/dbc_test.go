@@ -0,0 +1,127 @@
+package can
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+const testDBC = `VERSION ""
+
+BU_: ECU Dash Logger
+
+BO_ 100 Engine: 8 ECU
+ SG_ Temp : 7|12@0- (0.1,0) [-100|100] "C" Dash,Logger
+ SG_ EngineSpeed : 16|16@1+ (0.125,0) [0|8031.875] "rpm" Dash
+ SG_ Mode : 36|8@1+ (1,0) [0|0] "" Dash
+ SG_ Counter : 51|6@0+ (1,0) [0|63] "" Dash
+
+BO_ 2566844672 Mux: 2 ECU
+ SG_ Selector M : 0|4@1+ (1,0) [0|0] "" Dash
+ SG_ A m1 : 8|8@1- (1,-10) [0|0] "" Dash
+ SG_ B m2 : 8|8@1+ (2,0) [0|0] "" Dash
+
+CM_ SG_ 100 Temp "Coolant temperature";
+`
+
+func TestParseDBC(t *testing.T) {
+	db, err := ParseDBC(strings.NewReader(testDBC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Messages) != 2 {
+		t.Fatalf("expected 2 messages got %d", len(db.Messages))
+	}
+	m := db.Message(100, false)
+	if m == nil || m.Name != "Engine" || m.Length != 8 || m.Sender != "ECU" || len(m.Signals) != 4 {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	s := m.Signal("Temp")
+	if s == nil || s.StartBit != 7 || s.Length != 12 || s.LittleEndian || !s.Signed || s.Factor != 0.1 ||
+		s.Min != -100 || s.Max != 100 || s.Unit != "C" || len(s.Receivers) != 2 || s.MultiplexValue != -1 {
+		t.Errorf("unexpected signal %+v", s)
+	}
+	m = db.Message(0x18FEF100, true)
+	if m == nil || m.Name != "Mux" || !m.Signals[0].Multiplexor || m.Signals[2].MultiplexValue != 2 {
+		t.Errorf("unexpected message %+v", m)
+	}
+	if db.Message(0x18FEF100, false) != nil {
+		t.Error("expected no standard message 0x18FEF100")
+	}
+}
+
+func TestMessageDecode(t *testing.T) {
+	db, err := ParseDBC(strings.NewReader(testDBC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte{0xFF, 0x60, 0x20, 0x1C, 0xB0, 0x0A, 0x0A, 0x80}
+	v, err := db.Message(100, false).Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"Temp": -1, "EngineSpeed": 900, "Mode": 171, "Counter": 42}
+	for name, w := range want {
+		if math.Abs(v[name]-w) > 1e-9 {
+			t.Errorf("%s: expected %v got %v", name, w, v[name])
+		}
+	}
+
+	// Temp of 204.7 is out of range but still decoded
+	data[0], data[1] = 0x7F, 0xF0
+	v, err = db.Message(100, false).Decode(data)
+	if !errors.Is(err, ErrOutOfRange) || math.Abs(v["Temp"]-204.7) > 1e-9 || v["EngineSpeed"] != 900 {
+		t.Errorf("expected ErrOutOfRange with values got %v err %v", v, err)
+	}
+
+	if _, err := db.Message(100, false).Decode(data[:6]); !errors.Is(err, decoder.ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+}
+
+func TestMultiplexed(t *testing.T) {
+	db, err := ParseDBC(strings.NewReader(testDBC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Frame{ID: 0x18FEF100, Extended: true, Data: []byte{0x01, 0xFB}}
+	m, v, err := db.Decode(f)
+	if err != nil || m.Name != "Mux" {
+		t.Fatalf("unexpected message %v err %v", m, err)
+	}
+	if _, ok := v["B"]; ok || v["A"] != -15 || v["Selector"] != 1 {
+		t.Errorf("unexpected values %v", v)
+	}
+	f.Data = []byte{0x02, 0x05}
+	if _, v, _ := db.Decode(f); v["B"] != 10 {
+		t.Errorf("unexpected values %v", v)
+	}
+	f.ID = 0x200
+	if _, _, err := db.Decode(f); err != ErrUnknownMessage {
+		t.Errorf("expected ErrUnknownMessage got %v", err)
+	}
+}
+
+func TestParseDBCErrors(t *testing.T) {
+	tests := []struct {
+		dbc  string
+		line int
+	}{
+		{` SG_ A : 0|8@1+ (1,0) [0|0] "" X`, 1},
+		{"BO_ 1 A: 8 X\n SG_ A : 0|8@2+ (1,0) [0|0] \"\" X", 2},
+		{"BO_ 1 A: 8 X\n SG_ A : 0|8@1+ (1,0 [0|0] \"\" X", 2},
+		{"BO_ 1 A: 8 X\n SG_ A : 0|0@1+ (1,0) [0|0] \"\" X", 2},
+		{"BO_ 1 A: 8 X\n\nBO_ 1 B: 8 X", 3},
+		{"BO_ x A: 8 X", 1},
+	}
+	for _, test := range tests {
+		_, err := ParseDBC(strings.NewReader(test.dbc))
+		var le *LineError
+		if !errors.Is(err, ErrMalformedDBC) || !errors.As(err, &le) || le.Line != test.line {
+			t.Errorf("%q: expected ErrMalformedDBC on line %d got %v", test.dbc, test.line, err)
+		}
+	}
+}
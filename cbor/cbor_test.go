@@ -0,0 +1,203 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Examples from RFC 8949 appendix A
+func TestDecode(t *testing.T) {
+	bigNeg, _ := new(big.Int).SetString("-18446744073709551617", 10)
+	bigPos, _ := new(big.Int).SetString("18446744073709551616", 10)
+
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"00", uint64(0)},
+		{"17", uint64(23)},
+		{"1818", uint64(24)},
+		{"1903e8", uint64(1000)},
+		{"1b000000e8d4a51000", uint64(1000000000000)},
+		{"1bffffffffffffffff", uint64(18446744073709551615)},
+		{"c249010000000000000000", bigPos},
+		{"3bffffffffffffffff", new(big.Int).Add(bigNeg, big.NewInt(1))},
+		{"c349010000000000000000", bigNeg},
+		{"20", int64(-1)},
+		{"3863", int64(-100)},
+		{"f90000", float64(0)},
+		{"f93c00", float64(1)},
+		{"f97bff", float64(65504)},
+		{"fa47c35000", float64(100000)},
+		{"fb3ff199999999999a", 1.1},
+		{"f9c400", float64(-4)},
+		{"f97c00", math.Inf(1)},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"f7", Undefined{}},
+		{"f0", Simple(16)},
+		{"f8ff", Simple(255)},
+		{"c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"c11a514b67b0", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"c1fb41d452d9ec200000", time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC)},
+		{"d74401020304", Tag{Number: 23, Content: []byte{1, 2, 3, 4}}},
+		{"40", []byte{}},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"60", ""},
+		{"6449455446", "IETF"},
+		{"62c3bc", "ü"},
+		{"80", []interface{}{}},
+		{"83010203", []interface{}{uint64(1), uint64(2), uint64(3)}},
+		{"8301820203820405", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"a0", map[interface{}]interface{}{}},
+		{"a201020304", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		{"a26161016162820203", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []interface{}{}},
+		{"9f018202039f0405ffff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"bf61610161629f0203ffff", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"a1d74101f5", map[interface{}]interface{}{Tag{Number: 23, Content: "\x01"}: true}},
+	}
+
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.input))
+		v, err := NewDecoder(p).Decode()
+		if err != nil {
+			t.Errorf("%s: got unexpected err: %s", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.expect) {
+			if a, ok := v.(*big.Int); ok && a.Cmp(test.expect.(*big.Int)) == 0 {
+				continue
+			}
+			if a, ok := v.(time.Time); ok && a.Equal(test.expect.(time.Time)) {
+				continue
+			}
+			t.Errorf("%s: expected %#v got %#v", test.input, test.expect, v)
+		}
+		if !p.EOF() {
+			t.Errorf("%s: expected EOF at %d", test.input, p.Index())
+		}
+	}
+
+	p := decoder.New(mustHex("f97e00"))
+	if v, err := NewDecoder(p).Decode(); err != nil || !math.IsNaN(v.(float64)) {
+		t.Errorf("expected NaN got %v err %v", v, err)
+	}
+}
+
+func TestDecodeKeepsByteOrder(t *testing.T) {
+	p := decoder.New([]byte{0x19, 0x01, 0x02, 0x03, 0x04})
+	p.SetLittleEndian()
+	if v, err := NewDecoder(p).Decode(); err != nil || v != uint64(0x0102) {
+		t.Errorf("expected 0x0102 got %v err %v", v, err)
+	}
+	if v := p.Uint16(); v != 0x0403 {
+		t.Errorf("expected the packet to still be little endian got 0x%04X", v)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		err    error
+	}{
+		{"1c", 0, ErrMalformed},
+		{"ff", 0, ErrMalformed},
+		{"8201", 0, decoder.ErrReadPastEndData},
+		{"83018101", 4, decoder.ErrReadPastEndData},
+		{"820181", 2, decoder.ErrReadPastEndData},
+		{"1903", 0, decoder.ErrReadPastEndData},
+		{"5affffffff00", 0, ErrMaxLength},
+		{"5a0000ffff00", 0, decoder.ErrReadPastEndData},
+		{"5f4101610200ff", 3, ErrMalformed},
+		{"a18001", 1, ErrUnhashableKey},
+		{"c06131", 0, ErrMalformed},
+		{"f818", 0, ErrMalformed},
+		{"818181818181", 3, ErrMaxDepth},
+	}
+
+	for _, test := range tests {
+		d := NewDecoder(decoder.New(mustHex(test.input)))
+		d.MaxDepth = 3
+		d.MaxLength = 1 << 16
+		_, err := d.Decode()
+		var e *decoder.OffsetError
+		if !errors.As(err, &e) || e.Offset != test.offset || !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v at %d got %v", test.input, test.err, test.offset, err)
+		}
+	}
+}
+
+type reading struct {
+	Device string    `cbor:"dev"`
+	Temp   float64   `cbor:"t"`
+	Count  int       `cbor:"1"`
+	Tags   []string  `cbor:"tags"`
+	Raw    []byte    `cbor:"raw"`
+	At     time.Time `cbor:"at"`
+	Flags  map[string]bool
+	Opt    *uint16 `cbor:"opt"`
+	Skip   string  `cbor:"-"`
+	Any    interface{}
+}
+
+func TestUnmarshal(t *testing.T) {
+	// {"dev": "s1", "t": 21.5, 1: 7, "tags": ["a", "b"], "raw": h'0102', "at": 1(1363896240),
+	//  "flags": {"ok": true}, "opt": 9, "-": "x", "any": [1]}
+	b := mustHex("aa" +
+		"63646576" + "627331" +
+		"6174" + "f94d60" +
+		"01" + "07" +
+		"6474616773" + "82" + "6161" + "6162" +
+		"63726177" + "420102" +
+		"626174" + "c11a514b67b0" +
+		"65666c616773" + "a1" + "626f6b" + "f5" +
+		"636f7074" + "09" +
+		"612d" + "6178" +
+		"63616e79" + "8101")
+
+	var r reading
+	if err := Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	opt := uint16(9)
+	expect := reading{
+		Device: "s1",
+		Temp:   21.5,
+		Count:  7,
+		Tags:   []string{"a", "b"},
+		Raw:    []byte{1, 2},
+		At:     time.Unix(1363896240, 0).UTC(),
+		Flags:  map[string]bool{"ok": true},
+		Opt:    &opt,
+		Any:    []interface{}{uint64(1)},
+	}
+	if !reflect.DeepEqual(r, expect) {
+		t.Errorf("expected %+v got %+v", expect, r)
+	}
+
+	var n uint8
+	err := Unmarshal(mustHex("190100"), &n)
+	if _, ok := err.(*UnmarshalTypeError); !ok {
+		t.Errorf("expected *UnmarshalTypeError got %v", err)
+	}
+}
@@ -0,0 +1,393 @@
+// Package cbor decodes CBOR (RFC 8949) data using a *decoder.Packet, into interface{} values or tagged structs.
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformed = errors.New("malformed cbor")
+var ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+var ErrMaxLength = errors.New("maximum length exceeded")
+var ErrUnhashableKey = errors.New("map key can not be used in a go map")
+
+// Default limits for untrusted input
+const (
+	DefaultMaxDepth  = 32
+	DefaultMaxLength = 1 << 20
+)
+
+// Major types
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Well known tags
+const (
+	TagDateTimeString = 0 // RFC 3339 date/time string
+	TagEpochDateTime  = 1 // Seconds since the epoch, int or float
+	TagPosBignum      = 2 // Positive bignum byte string
+	TagNegBignum      = 3 // Negative bignum byte string
+)
+
+// Tag is a tagged item with a tag number not handled by the decoder
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// Simple is a simple value other than false, true, null & undefined
+type Simple uint8
+
+// Undefined is the undefined simple value
+type Undefined struct{}
+
+// Decoder reads CBOR items from a packet. Decode returns:
+//
+//	unsigned & negative ints as uint64 & int64 (or *big.Int if too large), floats as float64,
+//	byte strings as []byte, text strings as string, arrays as []interface{},
+//	maps as map[interface{}]interface{}, false/true as bool, null as nil,
+//	tags 0 & 1 as time.Time, tags 2 & 3 as *big.Int and any other tag as Tag
+type Decoder struct {
+	p         *decoder.Packet
+	depth     int
+	MaxDepth  int // The deepest nesting of arrays, maps & tags allowed
+	MaxLength int // The largest string length or item count allowed
+}
+
+// NewDecoder returns a Decoder reading from the packet's current position, the packet's byte order
+// is left as it is
+func NewDecoder(p *decoder.Packet) *Decoder {
+	return &Decoder{
+		p:         p,
+		MaxDepth:  DefaultMaxDepth,
+		MaxLength: DefaultMaxLength,
+	}
+}
+
+// Decode reads the next item. Errors are an *decoder.OffsetError with the offset of the item
+func (d *Decoder) Decode() (interface{}, error) {
+	d.depth = 0
+	return d.item()
+}
+
+// item reads a single item
+func (d *Decoder) item() (interface{}, error) {
+	offset := d.p.Index()
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	fail := func(err error) (interface{}, error) {
+		return nil, &decoder.OffsetError{Offset: offset, Err: err}
+	}
+	indefinite := info == 31
+
+	switch major {
+	case majorUint:
+		return arg, nil
+
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			v := new(big.Int).SetUint64(arg)
+			return v.Neg(v).Sub(v, big.NewInt(1)), nil
+		}
+		return -1 - int64(arg), nil
+
+	case majorBytes, majorText:
+		var b []byte
+		if indefinite {
+			b, err = d.chunks(major)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if err := d.checkLength(offset, arg); err != nil {
+				return nil, err
+			}
+			b = d.p.BytesCopy(int(arg))
+			if d.p.Err != nil {
+				return fail(d.p.Err)
+			}
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+
+	case majorArray:
+		if err := d.enter(offset); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		var v []interface{}
+		if !indefinite {
+			if err := d.checkLength(offset, arg); err != nil {
+				return nil, err
+			}
+			v = make([]interface{}, 0, int(arg))
+		}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			if len(v) >= d.MaxLength {
+				return fail(ErrMaxLength)
+			}
+			x, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, x)
+		}
+		if v == nil {
+			v = []interface{}{}
+		}
+		return v, nil
+
+	case majorMap:
+		if err := d.enter(offset); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		if !indefinite {
+			if err := d.checkLength(offset, arg); err != nil {
+				return nil, err
+			}
+		}
+		v := make(map[interface{}]interface{})
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			if len(v) >= d.MaxLength {
+				return fail(ErrMaxLength)
+			}
+			keyOffset := d.p.Index()
+			k, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+			k, ok := hashable(k)
+			if !ok {
+				return nil, &decoder.OffsetError{Offset: keyOffset, Err: ErrUnhashableKey}
+			}
+			x, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+			v[k] = x
+		}
+		return v, nil
+
+	case majorTag:
+		if err := d.enter(offset); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		content, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		v, err := tagged(arg, content)
+		if err != nil {
+			return fail(err)
+		}
+		return v, nil
+	}
+
+	// Major type 7, simple values & floats
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	case 23:
+		return Undefined{}, nil
+	case 24:
+		if arg < 32 {
+			return fail(ErrMalformed)
+		}
+		return Simple(arg), nil
+	case 25:
+		return float64(decoder.Float16frombits(uint16(arg))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	case 31:
+		return fail(ErrMalformed) // Unexpected break
+	}
+	if info < 20 {
+		return Simple(info), nil
+	}
+	return fail(ErrMalformed)
+}
+
+// head reads an item's initial byte and argument
+func (d *Decoder) head() (major byte, info byte, arg uint64, err error) {
+	defer d.p.SetByteOrder(d.p.ByteOrder())
+	d.p.SetBigEndian()
+	offset := d.p.Index()
+	b := d.p.Byte()
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		arg = uint64(d.p.Byte())
+	case info == 25:
+		arg = uint64(d.p.Uint16())
+	case info == 26:
+		arg = uint64(d.p.Uint32())
+	case info == 27:
+		arg = d.p.Uint64()
+	case info == 31 && (major >= majorBytes && major <= majorMap || major == majorSimple):
+		// Indefinite length or break
+	default:
+		return 0, 0, 0, &decoder.OffsetError{Offset: offset, Err: ErrMalformed}
+	}
+	if d.p.Err != nil {
+		return 0, 0, 0, &decoder.OffsetError{Offset: offset, Err: d.p.Err}
+	}
+	return major, info, arg, nil
+}
+
+// chunks reads the definite length chunks of an indefinite length string
+func (d *Decoder) chunks(major byte) ([]byte, error) {
+	var v []byte
+	for !d.isBreak() {
+		offset := d.p.Index()
+		m, info, arg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || info == 31 {
+			return nil, &decoder.OffsetError{Offset: offset, Err: ErrMalformed}
+		}
+		if err := d.checkLength(offset, arg); err != nil {
+			return nil, err
+		}
+		if uint64(len(v))+arg > uint64(d.MaxLength) {
+			return nil, &decoder.OffsetError{Offset: offset, Err: ErrMaxLength}
+		}
+		v = append(v, d.p.Bytes(int(arg))...)
+		if d.p.Err != nil {
+			return nil, &decoder.OffsetError{Offset: offset, Err: d.p.Err}
+		}
+	}
+	if v == nil {
+		v = []byte{}
+	}
+	return v, nil
+}
+
+// isBreak consumes a break code if it is next
+func (d *Decoder) isBreak() bool {
+	if b, err := d.p.PeekByte(); err == nil && b == 0xff {
+		d.p.Byte()
+		return true
+	}
+	return false
+}
+
+// checkLength checks a length or count against the limit and the remaining data,
+// as every item takes at least a byte
+func (d *Decoder) checkLength(offset int, n uint64) error {
+	if n > uint64(d.MaxLength) {
+		return &decoder.OffsetError{Offset: offset, Err: ErrMaxLength}
+	}
+	if n > uint64(d.p.RemainingLength()) {
+		return &decoder.OffsetError{Offset: offset, Err: decoder.ErrReadPastEndData}
+	}
+	return nil
+}
+
+func (d *Decoder) enter(offset int) error {
+	d.depth++
+	if d.depth > d.MaxDepth {
+		return &decoder.OffsetError{Offset: offset, Err: ErrMaxDepth}
+	}
+	return nil
+}
+
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// hashable returns a version of k that can be used as a go map key
+func hashable(k interface{}) (interface{}, bool) {
+	switch k := k.(type) {
+	case []byte:
+		return string(k), true
+	case []interface{}, map[interface{}]interface{}, *big.Int:
+		return nil, false
+	case Tag:
+		c, ok := hashable(k.Content)
+		if !ok {
+			return nil, false
+		}
+		return Tag{Number: k.Number, Content: c}, true
+	}
+	return k, true
+}
+
+// tagged converts the content of the well known tags
+func tagged(number uint64, content interface{}) (interface{}, error) {
+	switch number {
+	case TagDateTimeString:
+		s, ok := content.(string)
+		if !ok {
+			return nil, ErrMalformed
+		}
+		v, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		return v, nil
+
+	case TagEpochDateTime:
+		switch v := content.(type) {
+		case uint64:
+			if v > math.MaxInt64 {
+				return nil, ErrMalformed
+			}
+			return time.Unix(int64(v), 0).UTC(), nil
+		case int64:
+			return time.Unix(v, 0).UTC(), nil
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, ErrMalformed
+			}
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		}
+		return nil, ErrMalformed
+
+	case TagPosBignum, TagNegBignum:
+		b, ok := content.([]byte)
+		if !ok {
+			return nil, ErrMalformed
+		}
+		v := new(big.Int).SetBytes(b)
+		if number == TagNegBignum {
+			v.Neg(v).Sub(v, big.NewInt(1))
+		}
+		return v, nil
+	}
+	return Tag{Number: number, Content: content}, nil
+}
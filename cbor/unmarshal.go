@@ -0,0 +1,252 @@
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// UnmarshalTypeError describes a CBOR value that can not be stored in a go type
+type UnmarshalTypeError struct {
+	Value interface{}
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("cbor: can not store %T in %s", e.Value, e.Type)
+}
+
+// Unmarshal decodes a single CBOR item into v, see Decoder.DecodeInto
+func Unmarshal(b []byte, v interface{}) error {
+	return NewDecoder(decoder.New(b)).DecodeInto(v)
+}
+
+// DecodeInto reads the next item into v, which must be a non nil pointer.
+// Maps are stored in structs using the field's `cbor:"name"` tag, or the field name ignoring case.
+// A tag name that is a number matches integer map keys, as used by COSE & CWT, and `cbor:"-"` skips a field
+func (d *Decoder) DecodeInto(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("cbor: DecodeInto needs a non nil pointer")
+	}
+	x, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	return assign(rv.Elem(), x)
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	bigIntType = reflect.TypeOf(big.Int{})
+	tagType    = reflect.TypeOf(Tag{})
+)
+
+func assign(rv reflect.Value, x interface{}) error {
+	mismatch := &UnmarshalTypeError{Value: x, Type: rv.Type()}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		if x == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+	if x == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return assign(rv.Elem(), x)
+	}
+	if t, ok := x.(Tag); ok && rv.Type() != tagType {
+		return assign(rv, t.Content)
+	}
+
+	switch rv.Type() {
+	case timeType, bigIntType, tagType:
+		xv := reflect.ValueOf(x)
+		if xv.Kind() == reflect.Ptr {
+			xv = xv.Elem()
+		}
+		if xv.Type() != rv.Type() {
+			return mismatch
+		}
+		rv.Set(xv)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, ok := x.(bool)
+		if !ok {
+			return mismatch
+		}
+		rv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch x := x.(type) {
+		case uint64:
+			if x > math.MaxInt64 {
+				return mismatch
+			}
+			n = int64(x)
+		case int64:
+			n = x
+		default:
+			return mismatch
+		}
+		if rv.OverflowInt(n) {
+			return mismatch
+		}
+		rv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := x.(uint64)
+		if !ok || rv.OverflowUint(n) {
+			return mismatch
+		}
+		rv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		switch x := x.(type) {
+		case float64:
+			rv.SetFloat(x)
+		case uint64:
+			rv.SetFloat(float64(x))
+		case int64:
+			rv.SetFloat(float64(x))
+		default:
+			return mismatch
+		}
+
+	case reflect.String:
+		s, ok := x.(string)
+		if !ok {
+			return mismatch
+		}
+		rv.SetString(s)
+
+	case reflect.Slice:
+		if b, ok := x.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes(b)
+			return nil
+		}
+		a, ok := x.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		s := reflect.MakeSlice(rv.Type(), len(a), len(a))
+		for i := range a {
+			if err := assign(s.Index(i), a[i]); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+
+	case reflect.Array:
+		if b, ok := x.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			if len(b) != rv.Len() {
+				return mismatch
+			}
+			reflect.Copy(rv, reflect.ValueOf(b))
+			return nil
+		}
+		a, ok := x.([]interface{})
+		if !ok || len(a) != rv.Len() {
+			return mismatch
+		}
+		for i := range a {
+			if err := assign(rv.Index(i), a[i]); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		m, ok := x.(map[interface{}]interface{})
+		if !ok {
+			return mismatch
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		}
+		for k, v := range m {
+			kv := reflect.New(rv.Type().Key()).Elem()
+			if err := assign(kv, k); err != nil {
+				return err
+			}
+			vv := reflect.New(rv.Type().Elem()).Elem()
+			if err := assign(vv, v); err != nil {
+				return err
+			}
+			rv.SetMapIndex(kv, vv)
+		}
+
+	case reflect.Struct:
+		m, ok := x.(map[interface{}]interface{})
+		if !ok {
+			return mismatch
+		}
+		return assignStruct(rv, m)
+
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+func assignStruct(rv reflect.Value, m map[interface{}]interface{}) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // Unexported
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("cbor"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		x, ok := m[name]
+		if !ok {
+			if n, err := strconv.ParseInt(name, 10, 64); err == nil {
+				if n >= 0 {
+					x, ok = m[uint64(n)]
+				} else {
+					x, ok = m[n]
+				}
+			}
+		}
+		if !ok {
+			for k, v := range m {
+				if s, isString := k.(string); isString && strings.EqualFold(s, name) {
+					x, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := assign(rv.Field(i), x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
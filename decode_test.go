@@ -348,3 +348,37 @@ func Test_Uint32(t *testing.T) {
 		}
 	}
 }
+
+func Test_Float16(t *testing.T) {
+	tests := []struct {
+		input  []byte
+		expect float32
+	}{
+		{[]byte{0x00, 0x00}, 0},
+		{[]byte{0x3c, 0x00}, 1},
+		{[]byte{0x3e, 0x00}, 1.5},
+		{[]byte{0xc4, 0x00}, -4},
+		{[]byte{0x7b, 0xff}, 65504},
+		{[]byte{0x00, 0x01}, 5.960464477539063e-8},
+		{[]byte{0x7c, 0x00}, float32(math.Inf(1))},
+		{[]byte{0xfc, 0x00}, float32(math.Inf(-1))},
+	}
+	for _, test := range tests {
+		p := New(test.input)
+		f := p.Float16()
+		if p.Err != nil {
+			t.Errorf("got unexpected err: %s", p.Err)
+		}
+		if f != test.expect {
+			t.Errorf("with % X expected %g got %g", test.input, test.expect, f)
+		}
+		if f := Float16frombits(uint16(test.input[0])<<8 | uint16(test.input[1])); f != test.expect {
+			t.Errorf("Float16frombits with % X expected %g got %g", test.input, test.expect, f)
+		}
+	}
+
+	p := New([]byte{0x7e, 0x00})
+	if f := p.Float16(); !math.IsNaN(float64(f)) {
+		t.Errorf("expected NaN got %g", f)
+	}
+}
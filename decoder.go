@@ -5,9 +5,9 @@
 package decoder
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 )
 
 type Packet struct {
@@ -16,11 +16,37 @@ type Packet struct {
 	idx    int              // The current idx we've read up to
 	Err    error            // The last error
 	endian binary.ByteOrder // The endian to use for decoding
+
+	encoding     TextEncoding // The character set used by string reads
+	maxLength    int          // The largest length prefixed read allowed, 0 for no limit
+	interner     *Interner    // Optional store of repeated strings
+	skipped      int          // The number of bytes skipped by the last seek
+	lineConfig   *LineConfig  // How lines are split, nil for DefaultLineConfig
+	fieldOptions FieldOptions // Quoting used when splitting fields
+	maxDecoded   int          // The largest decoded sub-region allowed, 0 for DefaultMaxDecodedSize
+	bit          int          // The absolute bit position, only valid while it is within the byte at idx
 }
 
 var ErrReadPastEndData = errors.New("read past of end of data")
 var ErrReadInvalidLength = errors.New("invalid length")
 var ErrReadNoData = errors.New("no data")
+var ErrInvalidVarint = errors.New("invalid varint")
+var ErrNoMatch = errors.New("no match")
+
+// OffsetError records the offset in the data at which an error occurred
+type OffsetError struct {
+	Offset int
+	Err    error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Err, e.Offset)
+}
+
+// Unwrap returns the underlying error for use with errors.Is
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
 
 // New returns a loaded packet ready for reading
 func New(b []byte) *Packet {
@@ -33,23 +59,16 @@ func New(b []byte) *Packet {
 	}
 }
 
-// Seek Byteto the next instance of the given byte
-func (p *Packet) SeekByte(b byte) bool {
-	return p.Seek([]byte{b})
-}
-
-// Seek to the next instance of the given bytes
-func (p *Packet) Seek(b []byte) bool {
-	if len(b) == 0 {
-		return false
-	}
-	for i := p.idx; i < len(p.buf)+len(b)-1; i++ {
-		if p.buf[i] == b[0] && bytes.Compare(p.buf[i:i+len(b)], b) == 0 {
-			p.idx = i
-			return true
-		}
-	}
-	return false
+// Load resets the packet to read the given data, keeping the text encoding, max length & interner settings.
+// It allows a packet to be reused without allocating
+func (p *Packet) Load(b []byte) {
+	p.buf = b
+	p.length = len(b)
+	p.idx = 0
+	p.bit = 0
+	p.skipped = 0
+	p.Err = nil
+	p.endian = binary.BigEndian
 }
 
 // Index returns the current pointer position
@@ -87,18 +106,45 @@ func (p *Packet) SetBigEndian() {
 	p.endian = binary.BigEndian
 }
 
+// ByteOrder returns the byte order used by reads, allowing it to be put back with SetByteOrder
+func (p *Packet) ByteOrder() binary.ByteOrder {
+	return p.endian
+}
+
+// SetByteOrder sets the byte order used by future reads
+func (p *Packet) SetByteOrder(o binary.ByteOrder) {
+	p.endian = o
+}
+
 // Reset moves the internal read point back to the start
 func (p *Packet) Reset() {
 	p.idx = 0
+	p.bit = 0
 	p.Err = nil
 }
 
-// Rewind moves the internal pointer backwards (or forward if passed a negative value)
+// Rewind moves the internal pointer backwards (or forward if passed a negative value), the end of the data
+// being valid. Moving outside of the data returns ErrReadPastEndData
 func (p *Packet) Rewind(i int) error {
 	idx := p.idx - i
-	if idx < 0 || idx >= p.length {
+	if idx < 0 || idx > p.length {
 		return ErrReadPastEndData
 	}
-	p.idx = idx
+	p.setIndex(idx)
 	return nil
 }
+
+// SeekTo moves the internal pointer to the given absolute offset, the end of the data being valid
+func (p *Packet) SeekTo(offset int) error {
+	if offset < 0 || offset > p.length {
+		return &OffsetError{Offset: offset, Err: ErrReadPastEndData}
+	}
+	p.setIndex(offset)
+	return nil
+}
+
+// setIndex moves the internal pointer, the bit position moving to the start of that byte
+func (p *Packet) setIndex(i int) {
+	p.idx = i
+	p.bit = i * 8
+}
@@ -0,0 +1,184 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding is the character set used to convert raw bytes into strings
+type TextEncoding int
+
+// Supported text encodings
+const (
+	EncodingUTF8        TextEncoding = iota // Bytes are used as is (the default)
+	EncodingLatin1                          // ISO 8859-1
+	EncodingWindows1252                     // Windows code page 1252
+	EncodingEBCDIC                          // EBCDIC code page 037
+)
+
+// textCodec maps every byte value to a rune, a nil table means no conversion
+type textCodec struct {
+	table *[256]rune
+	ascii bool // true if 0x00-0x7f map to themselves, allowing a fast path
+}
+
+var textCodecs = [...]textCodec{
+	EncodingUTF8:        {nil, true},
+	EncodingLatin1:      {&latin1Table, true},
+	EncodingWindows1252: {&windows1252Table, true},
+	EncodingEBCDIC:      {&ebcdic037Table, false},
+}
+
+var latin1Table, windows1252Table [256]rune
+
+// windows1252High holds 0x80-0x9f, the only range that differs from Latin-1.
+// Unassigned positions map to the matching C1 control as per WHATWG
+var windows1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+var ebcdic037Table = [256]rune{
+	0x0000, 0x0001, 0x0002, 0x0003, 0x009C, 0x0009, 0x0086, 0x007F,
+	0x0097, 0x008D, 0x008E, 0x000B, 0x000C, 0x000D, 0x000E, 0x000F,
+	0x0010, 0x0011, 0x0012, 0x0013, 0x009D, 0x0085, 0x0008, 0x0087,
+	0x0018, 0x0019, 0x0092, 0x008F, 0x001C, 0x001D, 0x001E, 0x001F,
+	0x0080, 0x0081, 0x0082, 0x0083, 0x0084, 0x000A, 0x0017, 0x001B,
+	0x0088, 0x0089, 0x008A, 0x008B, 0x008C, 0x0005, 0x0006, 0x0007,
+	0x0090, 0x0091, 0x0016, 0x0093, 0x0094, 0x0095, 0x0096, 0x0004,
+	0x0098, 0x0099, 0x009A, 0x009B, 0x0014, 0x0015, 0x009E, 0x001A,
+	0x0020, 0x00A0, 0x00E2, 0x00E4, 0x00E0, 0x00E1, 0x00E3, 0x00E5,
+	0x00E7, 0x00F1, 0x00A2, 0x002E, 0x003C, 0x0028, 0x002B, 0x007C,
+	0x0026, 0x00E9, 0x00EA, 0x00EB, 0x00E8, 0x00ED, 0x00EE, 0x00EF,
+	0x00EC, 0x00DF, 0x0021, 0x0024, 0x002A, 0x0029, 0x003B, 0x00AC,
+	0x002D, 0x002F, 0x00C2, 0x00C4, 0x00C0, 0x00C1, 0x00C3, 0x00C5,
+	0x00C7, 0x00D1, 0x00A6, 0x002C, 0x0025, 0x005F, 0x003E, 0x003F,
+	0x00F8, 0x00C9, 0x00CA, 0x00CB, 0x00C8, 0x00CD, 0x00CE, 0x00CF,
+	0x00CC, 0x0060, 0x003A, 0x0023, 0x0040, 0x0027, 0x003D, 0x0022,
+	0x00D8, 0x0061, 0x0062, 0x0063, 0x0064, 0x0065, 0x0066, 0x0067,
+	0x0068, 0x0069, 0x00AB, 0x00BB, 0x00F0, 0x00FD, 0x00FE, 0x00B1,
+	0x00B0, 0x006A, 0x006B, 0x006C, 0x006D, 0x006E, 0x006F, 0x0070,
+	0x0071, 0x0072, 0x00AA, 0x00BA, 0x00E6, 0x00B8, 0x00C6, 0x00A4,
+	0x00B5, 0x007E, 0x0073, 0x0074, 0x0075, 0x0076, 0x0077, 0x0078,
+	0x0079, 0x007A, 0x00A1, 0x00BF, 0x00D0, 0x00DD, 0x00DE, 0x00AE,
+	0x005E, 0x00A3, 0x00A5, 0x00B7, 0x00A9, 0x00A7, 0x00B6, 0x00BC,
+	0x00BD, 0x00BE, 0x005B, 0x005D, 0x00AF, 0x00A8, 0x00B4, 0x00D7,
+	0x007B, 0x0041, 0x0042, 0x0043, 0x0044, 0x0045, 0x0046, 0x0047,
+	0x0048, 0x0049, 0x00AD, 0x00F4, 0x00F6, 0x00F2, 0x00F3, 0x00F5,
+	0x007D, 0x004A, 0x004B, 0x004C, 0x004D, 0x004E, 0x004F, 0x0050,
+	0x0051, 0x0052, 0x00B9, 0x00FB, 0x00FC, 0x00F9, 0x00FA, 0x00FF,
+	0x005C, 0x00F7, 0x0053, 0x0054, 0x0055, 0x0056, 0x0057, 0x0058,
+	0x0059, 0x005A, 0x00B2, 0x00D4, 0x00D6, 0x00D2, 0x00D3, 0x00D5,
+	0x0030, 0x0031, 0x0032, 0x0033, 0x0034, 0x0035, 0x0036, 0x0037,
+	0x0038, 0x0039, 0x00B3, 0x00DB, 0x00DC, 0x00D9, 0x00DA, 0x009F,
+}
+
+func init() {
+	for i := range latin1Table {
+		latin1Table[i] = rune(i)
+	}
+	windows1252Table = latin1Table
+	copy(windows1252Table[0x80:0xa0], windows1252High[:])
+}
+
+// SetEncoding sets the text encoding used by future string reads
+func (p *Packet) SetEncoding(e TextEncoding) {
+	p.encoding = e
+}
+
+// Encoding returns the text encoding used by string reads
+func (p *Packet) Encoding() TextEncoding {
+	return p.encoding
+}
+
+// text converts raw bytes to a string using the packet's text encoding
+func (p *Packet) text(b []byte) string {
+	if p.interner != nil {
+		return p.interner.intern(p.encoding, b)
+	}
+	return decodeText(p.encoding, b)
+}
+
+func decodeText(e TextEncoding, b []byte) string {
+	if e < 0 || int(e) >= len(textCodecs) || textCodecs[e].table == nil {
+		return string(b)
+	}
+	codec := textCodecs[e]
+	if codec.ascii && isASCII(b) {
+		return string(b)
+	}
+	buf := make([]byte, 0, len(b)*2)
+	var r [utf8.UTFMax]byte
+	for _, c := range b {
+		n := utf8.EncodeRune(r[:], codec.table[c])
+		buf = append(buf, r[:n]...)
+	}
+	return string(buf)
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// StringUTF16LE returns the little endian UTF-16 string of the given number of code units
+// at the internal pointer, stopping at the first 0x0000, and increments it accordingly
+func (p *Packet) StringUTF16LE(units int) string {
+	return p.stringUTF16(binary.LittleEndian, units)
+}
+
+// StringUTF16BE returns the big endian UTF-16 string of the given number of code units
+// at the internal pointer, stopping at the first 0x0000, and increments it accordingly
+func (p *Packet) StringUTF16BE(units int) string {
+	return p.stringUTF16(binary.BigEndian, units)
+}
+
+// CStringUTF16LE returns the little endian UTF-16 string at internal pointer (terminated with a 0x0000)
+// and increments it accordingly
+func (p *Packet) CStringUTF16LE() string {
+	return p.cStringUTF16(binary.LittleEndian)
+}
+
+// CStringUTF16BE returns the big endian UTF-16 string at internal pointer (terminated with a 0x0000)
+// and increments it accordingly
+func (p *Packet) CStringUTF16BE() string {
+	return p.cStringUTF16(binary.BigEndian)
+}
+
+func (p *Packet) stringUTF16(order binary.ByteOrder, units int) string {
+	if units < 0 || p.idx+units*2 > p.length {
+		p.Err = ErrReadPastEndData
+		return ""
+	}
+	u := make([]uint16, 0, units)
+	for i := 0; i < units; i++ {
+		c := order.Uint16(p.buf[p.idx+i*2:])
+		if c == 0x0000 {
+			break
+		}
+		u = append(u, c)
+	}
+	p.idx += units * 2
+	return string(utf16.Decode(u))
+}
+
+func (p *Packet) cStringUTF16(order binary.ByteOrder) string {
+	var u []uint16
+	for idx := p.idx; idx+1 < p.length; idx += 2 {
+		c := order.Uint16(p.buf[idx:])
+		if c == 0x0000 {
+			p.setIndex(idx + 2)
+			return string(utf16.Decode(u))
+		}
+		u = append(u, c)
+	}
+	p.Err = ErrReadPastEndData
+	return ""
+}
@@ -0,0 +1,89 @@
+package decoder
+
+import "testing"
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		encoding TextEncoding
+		input    []byte
+		expect   string
+	}{
+		{EncodingUTF8, []byte{0x41, 0xc3, 0xa9, 0x00}, "Aé"},
+		{EncodingLatin1, []byte{0x41, 0xe9, 0x00}, "Aé"},
+		{EncodingLatin1, []byte{0x41, 0x42, 0x00}, "AB"},
+		{EncodingWindows1252, []byte{0x80, 0x20, 0x99, 0xe9, 0x00}, "€ ™é"},
+		{EncodingWindows1252, []byte{0x81, 0x00}, "\u0081"},
+		{EncodingEBCDIC, []byte{0xc8, 0x85, 0x93, 0x93, 0x96, 0x40, 0xf1, 0xf2, 0x00}, "Hello 12"},
+	}
+
+	for _, test := range tests {
+		p := New(test.input)
+		p.SetEncoding(test.encoding)
+		s := p.CString()
+		if p.Err != nil {
+			t.Errorf("got unexpected err: %s", p.Err)
+		}
+		if s != test.expect {
+			t.Errorf("with % X expected '%s' got '%s'", test.input, test.expect, s)
+		}
+	}
+}
+
+func TestEncodingStringReaders(t *testing.T) {
+	p := New([]byte{
+		0x01, 0xe9, // Prefix byte len
+		0x00, 0x01, 0xe9, // Prefix uint16 len
+		0xe9, 0x00, 0x00, // Zero padded
+		0xe9, '|', // Delimited
+	})
+	p.SetEncoding(EncodingLatin1)
+
+	for i, s := range []string{
+		p.StringPrefixByteLen(),
+		p.StringPrefixUint16Len(),
+		p.StringZeroPadded(3),
+		p.StringByDelimiter('|'),
+	} {
+		if s != "é" {
+			t.Errorf("string %d: expected 'é' got '%s'", i, s)
+		}
+	}
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	p := New([]byte{
+		0x41, 0x00, 0xe9, 0x00, 0x3d, 0xd8, 0x00, 0xde, 0x00, 0x00, // LE "Aé😀" NULL
+		0x00, 0x41, 0x00, 0x42, 0x00, 0x00, 0x00, 0x00, // BE "AB" padded to 4 units
+		0xff,
+	})
+
+	s := p.CStringUTF16LE()
+	if s != "Aé😀" {
+		t.Errorf("expected 'Aé😀' got '%s'", s)
+	}
+	s = p.StringUTF16BE(4)
+	if s != "AB" {
+		t.Errorf("expected 'AB' got '%s'", s)
+	}
+	if b := p.Byte(); b != 0xff {
+		t.Errorf("expected 0xff got %X", b)
+	}
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+
+	p = New([]byte{0x41, 0x00, 0x42})
+	p.CStringUTF16LE()
+	if p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", p.Err)
+	}
+
+	p = New([]byte{0x41, 0x00, 0x42})
+	p.StringUTF16LE(2)
+	if p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", p.Err)
+	}
+}
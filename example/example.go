@@ -85,7 +85,7 @@ func Example1(buf []byte) {
 		if err := dec.Err; err != nil {
 			log.Fatalln("decoding string:", err)
 		}
-		log.Fatalf("Expected string to be 'ABC', got 'ABC'\n", s)
+		log.Fatalf("Expected string to be 'ABC', got '%s'\n", s)
 	}
 	log.Print("Read string ok")
 
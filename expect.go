@@ -0,0 +1,178 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// UnexpectedValueError is set as Err when an Expect function finds a different value
+type UnexpectedValueError struct {
+	Offset   int         // The offset of the value
+	Expected interface{} // The value, or values, expected
+	Actual   interface{} // The value found
+}
+
+func (e *UnexpectedValueError) Error() string {
+	return fmt.Sprintf("unexpected value at offset %d: expected %s got %s", e.Offset, formatValue(e.Expected), formatValue(e.Actual))
+}
+
+// OutOfRangeError is set as Err when a range checked read finds a value outside of the range
+type OutOfRangeError struct {
+	Offset int         // The offset of the value
+	Min    interface{} // The minimum allowed value
+	Max    interface{} // The maximum allowed value
+	Actual interface{} // The value found
+}
+
+func (e *OutOfRangeError) Error() string {
+	return fmt.Sprintf("value out of range at offset %d: expected %v to %v got %v", e.Offset, e.Min, e.Max, e.Actual)
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case byte:
+		return fmt.Sprintf("0x%02X", v)
+	case []byte:
+		return fmt.Sprintf("[% X]", v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// Expect returns true if the given bytes are at the internal pointer and increments it accordingly,
+// otherwise Err is set to an *UnexpectedValueError and the internal pointer is left unchanged
+func (p *Packet) Expect(b ...byte) bool {
+	if p.idx+len(b) > p.length {
+		p.Err = ErrReadPastEndData
+		return false
+	}
+	actual := p.buf[p.idx : p.idx+len(b)]
+	if !bytes.Equal(actual, b) {
+		p.Err = &UnexpectedValueError{
+			Offset:   p.idx,
+			Expected: copyBytes(b),
+			Actual:   copyBytes(actual),
+		}
+		return false
+	}
+	p.idx += len(b)
+	return true
+}
+
+// ExpectByte returns true if the given byte is at the internal pointer and increments it accordingly,
+// otherwise Err is set to an *UnexpectedValueError and the internal pointer is left unchanged
+func (p *Packet) ExpectByte(b byte) bool {
+	if p.idx >= p.length {
+		p.Err = ErrReadPastEndData
+		return false
+	}
+	if p.buf[p.idx] != b {
+		p.Err = &UnexpectedValueError{
+			Offset:   p.idx,
+			Expected: b,
+			Actual:   p.buf[p.idx],
+		}
+		return false
+	}
+	p.idx++
+	return true
+}
+
+// ExpectString returns true if the raw bytes of the given string are at the internal pointer and
+// increments it accordingly, otherwise Err is set to an *UnexpectedValueError and the internal pointer is left unchanged
+func (p *Packet) ExpectString(s string) bool {
+	if p.idx+len(s) > p.length {
+		p.Err = ErrReadPastEndData
+		return false
+	}
+	actual := p.buf[p.idx : p.idx+len(s)]
+	if string(actual) != s {
+		p.Err = &UnexpectedValueError{
+			Offset:   p.idx,
+			Expected: s,
+			Actual:   string(actual),
+		}
+		return false
+	}
+	p.idx += len(s)
+	return true
+}
+
+// ExpectOneOf returns the byte at the internal pointer if it is one of the given bytes and increments it accordingly,
+// otherwise Err is set to an *UnexpectedValueError and the internal pointer is left unchanged
+func (p *Packet) ExpectOneOf(b ...byte) byte {
+	if p.idx >= p.length {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	v := p.buf[p.idx]
+	if bytes.IndexByte(b, v) == -1 {
+		p.Err = &UnexpectedValueError{
+			Offset:   p.idx,
+			Expected: copyBytes(b),
+			Actual:   v,
+		}
+		return 0
+	}
+	p.idx++
+	return v
+}
+
+// The InRange functions read a value as normal, if it is outside of min to max (inclusive)
+// Err is set to an *OutOfRangeError and the internal pointer is left on the value
+
+// ByteInRange returns the byte at the internal pointer checking it is in the given range
+func (p *Packet) ByteInRange(min, max byte) byte {
+	idx := p.idx
+	v := p.Byte()
+	if p.Err == nil && (v < min || v > max) {
+		p.setIndex(idx)
+		p.Err = &OutOfRangeError{Offset: idx, Min: min, Max: max, Actual: v}
+	}
+	return v
+}
+
+// Uint16InRange returns the uint16 at the internal pointer checking it is in the given range
+func (p *Packet) Uint16InRange(min, max uint16) uint16 {
+	idx := p.idx
+	v := p.Uint16()
+	if p.Err == nil && (v < min || v > max) {
+		p.setIndex(idx)
+		p.Err = &OutOfRangeError{Offset: idx, Min: min, Max: max, Actual: v}
+	}
+	return v
+}
+
+// Uint32InRange returns the uint32 at the internal pointer checking it is in the given range
+func (p *Packet) Uint32InRange(min, max uint32) uint32 {
+	idx := p.idx
+	v := p.Uint32()
+	if p.Err == nil && (v < min || v > max) {
+		p.setIndex(idx)
+		p.Err = &OutOfRangeError{Offset: idx, Min: min, Max: max, Actual: v}
+	}
+	return v
+}
+
+// Uint64InRange returns the uint64 at the internal pointer checking it is in the given range
+func (p *Packet) Uint64InRange(min, max uint64) uint64 {
+	idx := p.idx
+	v := p.Uint64()
+	if p.Err == nil && (v < min || v > max) {
+		p.setIndex(idx)
+		p.Err = &OutOfRangeError{Offset: idx, Min: min, Max: max, Actual: v}
+	}
+	return v
+}
+
+// AsciiIntInRange returns the ASCII integer at the internal pointer checking it is in the given range
+func (p *Packet) AsciiIntInRange(min, max int) int {
+	idx := p.idx
+	v := p.AsciiInt()
+	if p.Err == nil && (v < min || v > max) {
+		p.setIndex(idx)
+		p.Err = &OutOfRangeError{Offset: idx, Min: min, Max: max, Actual: v}
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package decoder
+
+import "testing"
+
+func TestExpect(t *testing.T) {
+	p := New([]byte{STX, 'O', 'K', 0xde, 0xad, ACK, ETX})
+	if !p.ExpectByte(STX) {
+		t.Errorf("expected STX got err %v", p.Err)
+	}
+	if !p.ExpectString("OK") {
+		t.Errorf("expected OK got err %v", p.Err)
+	}
+	if !p.Expect(0xde, 0xad) {
+		t.Errorf("expected 0xdead got err %v", p.Err)
+	}
+	if b := p.ExpectOneOf(ACK, NAK); b != ACK {
+		t.Errorf("expected ACK got %X err %v", b, p.Err)
+	}
+	if p.ExpectByte(EOT) {
+		t.Error("expected EOT to not match")
+	}
+	e, ok := p.Err.(*UnexpectedValueError)
+	if !ok {
+		t.Fatalf("expected *UnexpectedValueError got %T", p.Err)
+	}
+	if e.Offset != 6 || e.Expected != byte(EOT) || e.Actual != byte(ETX) {
+		t.Errorf("unexpected error contents: %+v", e)
+	}
+	if e.Error() != "unexpected value at offset 6: expected 0x04 got 0x03" {
+		t.Errorf("unexpected error message: %s", e)
+	}
+	if p.Index() != 6 {
+		t.Errorf("expected index to be left at 6 got %d", p.Index())
+	}
+	if !p.ExpectByte(ETX) {
+		t.Errorf("expected ETX got err %v", p.Err)
+	}
+	if p.ExpectByte(ETX) || p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", p.Err)
+	}
+
+	p = New([]byte("NO"))
+	if p.ExpectString("OK") {
+		t.Error("expected OK to not match")
+	}
+	if e, ok := p.Err.(*UnexpectedValueError); !ok || e.Actual != "NO" {
+		t.Errorf("expected *UnexpectedValueError with actual NO got %v", p.Err)
+	}
+
+	p = New([]byte{EOT})
+	p.ExpectOneOf(ACK, NAK)
+	if e, ok := p.Err.(*UnexpectedValueError); !ok || e.Error() != "unexpected value at offset 0: expected [06 15] got 0x04" {
+		t.Errorf("unexpected error %v", p.Err)
+	}
+}
+
+func TestInRange(t *testing.T) {
+	p := New([]byte{0x00, 0x10, 0x01, 0x00, '4', '2'})
+	if v := p.Uint16InRange(1, 0x100); v != 0x10 || p.Err != nil {
+		t.Errorf("expected 0x10 got %X err %v", v, p.Err)
+	}
+	p.Uint16InRange(1, 0xff)
+	e, ok := p.Err.(*OutOfRangeError)
+	if !ok {
+		t.Fatalf("expected *OutOfRangeError got %T", p.Err)
+	}
+	if e.Offset != 2 || e.Actual != uint16(0x100) {
+		t.Errorf("unexpected error contents: %+v", e)
+	}
+	if p.Index() != 2 {
+		t.Errorf("expected index to be left at 2 got %d", p.Index())
+	}
+
+	p.Err = nil
+	p.Uint16()
+	if v := p.AsciiIntInRange(0, 100); v != 42 || p.Err != nil {
+		t.Errorf("expected 42 got %d err %v", v, p.Err)
+	}
+}
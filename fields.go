@@ -0,0 +1,103 @@
+package decoder
+
+import "errors"
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+var ErrInvalidEscape = errors.New("invalid escape")
+var ErrMissingDelimiter = errors.New("missing delimiter")
+
+// FieldOptions sets the quoting used by NextField & Fields, a 0 byte disables the option
+type FieldOptions struct {
+	Quote  byte // Fields starting with Quote run until the closing Quote, e.g. '"'
+	Escape byte // The byte after Escape is taken literally, if the same as Quote a doubled quote is a literal quote
+}
+
+// SetFieldOptions sets the quoting used by NextField & Fields
+func (p *Packet) SetFieldOptions(o FieldOptions) {
+	p.fieldOptions = o
+}
+
+// NextField returns the field at the internal pointer ended by the given delimiter and increments it past
+// the delimiter. Last is true when the field was ended by the end of the data rather than a delimiter,
+// an empty field after a trailing delimiter is returned with last set. Bad quoting or escapes set Err to
+// an *OffsetError
+func (p *Packet) NextField(delimiter byte) (field string, last bool) {
+	o := p.fieldOptions
+	idx := p.idx
+	quoted := o.Quote != 0 && idx < p.length && p.buf[idx] == o.Quote
+	if quoted {
+		idx++
+	}
+	start := idx
+	var unescaped []byte // Only used once an escape has been seen
+	for ; idx < p.length; idx++ {
+		c := p.buf[idx]
+		switch {
+		case quoted && c == o.Quote && o.Escape == o.Quote && idx+1 < p.length && p.buf[idx+1] == o.Quote:
+			unescaped = append(unescaped, p.buf[start:idx+1]...)
+			idx++
+			start = idx + 1
+		case quoted && c == o.Quote:
+			v := p.fieldText(unescaped, p.buf[start:idx])
+			idx++
+			if idx == p.length {
+				p.setIndex(idx)
+				return v, true
+			}
+			if p.buf[idx] != delimiter {
+				p.Err = &OffsetError{Offset: idx, Err: ErrMissingDelimiter}
+				return "", true
+			}
+			p.setIndex(idx + 1)
+			return v, false
+		case o.Escape != 0 && c == o.Escape && o.Escape != o.Quote:
+			if idx+1 >= p.length {
+				p.Err = &OffsetError{Offset: idx, Err: ErrInvalidEscape}
+				return "", true
+			}
+			unescaped = append(unescaped, p.buf[start:idx]...)
+			idx++
+			start = idx // The escaped byte is kept
+		case !quoted && c == delimiter:
+			v := p.fieldText(unescaped, p.buf[start:idx])
+			p.setIndex(idx + 1)
+			return v, false
+		}
+	}
+	if quoted {
+		p.Err = &OffsetError{Offset: p.idx, Err: ErrUnterminatedQuote}
+		return "", true
+	}
+	v := p.fieldText(unescaped, p.buf[start:idx])
+	p.setIndex(idx)
+	return v, true
+}
+
+// Fields returns all the remaining fields split by the given delimiter, or nil if there is no data left.
+// An Err set before the call is kept, reading stops at an error raised while splitting the fields
+func (p *Packet) Fields(delimiter byte) []string {
+	if p.idx >= p.length {
+		return nil
+	}
+	prevErr := p.Err
+	p.Err = nil
+	var v []string
+	for {
+		f, last := p.NextField(delimiter)
+		if p.Err != nil {
+			return v
+		}
+		v = append(v, f)
+		if last {
+			p.Err = prevErr
+			return v
+		}
+	}
+}
+
+func (p *Packet) fieldText(unescaped []byte, rest []byte) string {
+	if unescaped == nil {
+		return p.text(rest)
+	}
+	return p.text(append(unescaped, rest...))
+}
@@ -0,0 +1,91 @@
+package decoder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		options FieldOptions
+		delim   byte
+		input   string
+		expect  []string
+	}{
+		{FieldOptions{}, ',', "a,b,c", []string{"a", "b", "c"}},
+		{FieldOptions{}, ',', "a,,c,", []string{"a", "", "c", ""}},
+		{FieldOptions{}, ',', ",", []string{"", ""}},
+		{FieldOptions{}, ',', "", nil},
+		{FieldOptions{}, '|', "REC|12|x,y", []string{"REC", "12", "x,y"}},
+		{FieldOptions{Quote: '"', Escape: '"'}, ',', `"a,b","say ""hi""",c`, []string{"a,b", `say "hi"`, "c"}},
+		{FieldOptions{Quote: '"', Escape: '"'}, ',', `"",x`, []string{"", "x"}},
+		{FieldOptions{Quote: '"', Escape: '\\'}, ',', `"a\"b",c\,d`, []string{`a"b`, "c,d"}},
+		{FieldOptions{Escape: '\\'}, '|', `a\|b|c\\`, []string{"a|b", `c\`}},
+	}
+
+	for _, test := range tests {
+		p := New([]byte(test.input))
+		p.SetFieldOptions(test.options)
+		f := p.Fields(test.delim)
+		if p.Err != nil {
+			t.Errorf("with %s got unexpected err: %s", test.input, p.Err)
+		}
+		if !reflect.DeepEqual(f, test.expect) {
+			t.Errorf("with %s expected %q got %q", test.input, test.expect, f)
+		}
+	}
+}
+
+func TestFieldsWithPriorErr(t *testing.T) {
+	p := New([]byte("a,b,c"))
+	p.Err = ErrReadPastEndData
+	f := p.Fields(',')
+	if !reflect.DeepEqual(f, []string{"a", "b", "c"}) {
+		t.Errorf("expected [a b c] got %q", f)
+	}
+	if p.Err != ErrReadPastEndData {
+		t.Errorf("expected the earlier error to be kept got %v", p.Err)
+	}
+	if !p.EOF() {
+		t.Errorf("expected all fields read got index %d", p.Index())
+	}
+}
+
+func TestNextField(t *testing.T) {
+	p := New([]byte("a,b"))
+	f, last := p.NextField(',')
+	if f != "a" || last {
+		t.Errorf("expected 'a' & not last got '%s' & %t", f, last)
+	}
+	f, last = p.NextField(',')
+	if f != "b" || !last {
+		t.Errorf("expected 'b' & last got '%s' & %t", f, last)
+	}
+	if !p.EOF() {
+		t.Error("expected EOF")
+	}
+
+	tests := []struct {
+		input  string
+		offset int
+		err    error
+	}{
+		{`x,"abc`, 2, ErrUnterminatedQuote},
+		{`x,"abc"d,e`, 7, ErrMissingDelimiter},
+		{`x,abc\`, 5, ErrInvalidEscape},
+	}
+	for _, test := range tests {
+		p := New([]byte(test.input))
+		p.SetFieldOptions(FieldOptions{Quote: '"', Escape: '\\'})
+		p.NextField(',')
+		p.NextField(',')
+		var e *OffsetError
+		if !errors.As(p.Err, &e) || e.Offset != test.offset || e.Err != test.err {
+			t.Errorf("with %s expected %v at %d got %v", test.input, test.err, test.offset, p.Err)
+		}
+		if p.Index() != 2 {
+			t.Errorf("with %s expected index to be left at 2 got %d", test.input, p.Index())
+		}
+	}
+}
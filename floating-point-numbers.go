@@ -19,3 +19,33 @@ func (p *Packet) Float64() float64 {
 	}
 	return math.Float64frombits(i)
 }
+
+// Float16 returns the IEEE 754 half precision value as a float32 at the internal pointer and increments it accordingly
+func (p *Packet) Float16() float32 {
+	h := p.Uint16()
+	if p.Err != nil {
+		return 0
+	}
+	return Float16frombits(h)
+}
+
+// Float16frombits returns the float32 value of the IEEE 754 half precision bits
+func Float16frombits(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f: // Inf & NaN
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp != 0: // Normal, rebias the exponent from 15 to 127
+		return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+	case frac == 0:
+		return math.Float32frombits(sign)
+	}
+	// Subnormal, which is a normal float32
+	v := float32(math.Ldexp(float64(frac), -24))
+	if sign != 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package framing
+
+import decoder "github.com/kgolding/go-decoder"
+
+// COBS is Consistent Overhead Byte Stuffing, frames are delimited by 0x00
+var COBS = &Codec{
+	Name:      "COBS",
+	Delimiter: 0x00,
+	decode: func(frame []byte) ([]byte, error) {
+		return cobsDecode(frame, false)
+	},
+	encode: func(b []byte) []byte {
+		return cobsEncode(b, false)
+	},
+}
+
+// COBSR is the COBS/R variant, which saves a byte on most frames by replacing the final
+// code byte with the last data byte when it is larger
+var COBSR = &Codec{
+	Name:      "COBS/R",
+	Delimiter: 0x00,
+	decode: func(frame []byte) ([]byte, error) {
+		return cobsDecode(frame, true)
+	},
+	encode: func(b []byte) []byte {
+		return cobsEncode(b, true)
+	},
+}
+
+func cobsDecode(frame []byte, reduced bool) ([]byte, error) {
+	v := make([]byte, 0, len(frame))
+	for i := 0; i < len(frame); {
+		code := int(frame[i])
+		if code == 0 {
+			return nil, &decoder.OffsetError{Offset: i, Err: ErrMalformedFrame}
+		}
+		if i+code > len(frame) {
+			if !reduced {
+				return nil, &decoder.OffsetError{Offset: i, Err: ErrMalformedFrame}
+			}
+			// The code byte was replaced with the last data byte
+			v = append(v, frame[i+1:]...)
+			return append(v, byte(code)), nil
+		}
+		v = append(v, frame[i+1:i+code]...)
+		i += code
+		if code < 0xff && i < len(frame) {
+			v = append(v, 0x00)
+		}
+	}
+	return v, nil
+}
+
+func cobsEncode(b []byte, reduced bool) []byte {
+	v := make([]byte, 1, len(b)+len(b)/254+3)
+	codeIdx, code := 0, byte(1)
+	for _, c := range b {
+		if c == 0x00 {
+			v[codeIdx] = code
+			codeIdx, code = len(v), 1
+			v = append(v, 0)
+			continue
+		}
+		v = append(v, c)
+		code++
+		if code == 0xff {
+			v[codeIdx] = code
+			codeIdx, code = len(v), 1
+			v = append(v, 0)
+		}
+	}
+	v[codeIdx] = code
+	if last := v[len(v)-1]; reduced && code > 1 && last > code {
+		v[codeIdx] = last
+		v = v[:len(v)-1]
+	}
+	return append(v, 0x00)
+}
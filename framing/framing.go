@@ -0,0 +1,127 @@
+// Package framing decodes and encodes byte stuffed frames, SLIP (RFC 1055), COBS & COBS/R, and HDLC-like
+// async framing (RFC 1662), returning each decoded frame as a *decoder.Packet.
+package framing
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformedFrame = errors.New("malformed frame")
+var ErrBadFCS = errors.New("bad frame check sequence")
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// DefaultMaxFrameSize is the largest encoded frame a Reader accepts unless changed
+const DefaultMaxFrameSize = 64 << 10
+
+// Codec is a framing scheme where each frame ends with a delimiter byte that does not appear within it
+type Codec struct {
+	Name      string
+	Delimiter byte
+	decode    func(frame []byte) ([]byte, error)
+	encode    func(b []byte) []byte
+}
+
+// Decode unstuffs a single frame, with or without its delimiters, into a packet over the original bytes.
+// Errors are an *decoder.OffsetError with the offset in the frame
+func (c *Codec) Decode(frame []byte) (*decoder.Packet, error) {
+	start, end := 0, len(frame)
+	for start < end && frame[start] == c.Delimiter {
+		start++
+	}
+	for end > start && frame[end-1] == c.Delimiter {
+		end--
+	}
+	b, err := c.decode(frame[start:end])
+	if err != nil {
+		if e, ok := err.(*decoder.OffsetError); ok {
+			e.Offset += start
+		}
+		return nil, err
+	}
+	return decoder.New(b), nil
+}
+
+// Encode stuffs the given bytes into a single frame including its delimiters
+func (c *Codec) Encode(b []byte) []byte {
+	return c.encode(b)
+}
+
+// Reader reads frames from a stream
+type Reader struct {
+	r            *bufio.Reader
+	codec        *Codec
+	offset       int // The stream offset of the next byte to be read
+	MaxFrameSize int // The largest encoded frame allowed, 0 for DefaultMaxFrameSize
+}
+
+// NewReader returns a Reader of frames encoded with the given codec
+func NewReader(r io.Reader, c *Codec) *Reader {
+	return &Reader{
+		r:     bufio.NewReader(r),
+		codec: c,
+	}
+}
+
+// Next returns the next decoded frame, skipping empty frames. A malformed frame returns an
+// *decoder.OffsetError with the offset in the stream, the following call moves on to the next frame.
+// A partial frame at the end of the stream is decoded as if it were delimited, after which io.EOF is returned
+func (fr *Reader) Next() (*decoder.Packet, error) {
+	max := fr.MaxFrameSize
+	if max <= 0 {
+		max = DefaultMaxFrameSize
+	}
+	for {
+		start := fr.offset
+		frame, err := fr.readFrame(max)
+		if err == ErrFrameTooLarge {
+			return nil, &decoder.OffsetError{Offset: start, Err: err}
+		}
+		if len(frame) > 0 {
+			p, decodeErr := fr.codec.Decode(frame)
+			if e, ok := decodeErr.(*decoder.OffsetError); ok {
+				e.Offset += start
+			}
+			if decodeErr != nil || err == nil || err == io.EOF {
+				return p, decodeErr
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readFrame returns the bytes up to the next delimiter, which is not included, or ErrFrameTooLarge if
+// there are more than max
+func (fr *Reader) readFrame(max int) ([]byte, error) {
+	var frame []byte
+	tooLarge := false
+	for {
+		b, err := fr.r.ReadSlice(fr.codec.Delimiter)
+		fr.offset += len(b)
+		if err == nil {
+			b = b[:len(b)-1]
+		}
+		if !tooLarge {
+			frame = append(frame, b...)
+			if len(frame) > max {
+				tooLarge, frame = true, nil
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLarge {
+			// An over-size final frame is still reported, the next call then returns io.EOF
+			if err == nil || err == io.EOF {
+				err = ErrFrameTooLarge
+			}
+			return nil, err
+		}
+		return frame, err
+	}
+}
@@ -0,0 +1,172 @@
+package framing
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestSLIP(t *testing.T) {
+	payload := []byte{0x01, SLIPEnd, 0x02, SLIPEsc, 0x03}
+	frame := SLIP.Encode(payload)
+	expect := []byte{SLIPEnd, 0x01, SLIPEsc, SLIPEscEnd, 0x02, SLIPEsc, SLIPEscEsc, 0x03, SLIPEnd}
+	if !bytes.Equal(frame, expect) {
+		t.Errorf("expected % X got % X", expect, frame)
+	}
+	p, err := SLIP.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := p.PeekRemainingBytes(); !bytes.Equal(b, payload) {
+		t.Errorf("expected % X got % X", payload, b)
+	}
+
+	_, err = SLIP.Decode([]byte{SLIPEnd, 0x01, SLIPEsc, 0x02, SLIPEnd})
+	var e *decoder.OffsetError
+	if !errors.As(err, &e) || e.Offset != 2 || e.Err != decoder.ErrInvalidEscape {
+		t.Errorf("expected ErrInvalidEscape at 2 got %v", err)
+	}
+}
+
+func TestCOBS(t *testing.T) {
+	long := bytes.Repeat([]byte{0x11}, 254)
+	tests := []struct {
+		payload []byte
+		cobs    []byte
+		cobsr   []byte
+	}{
+		{[]byte{}, []byte{0x01, 0x00}, []byte{0x01, 0x00}},
+		{[]byte{0x00}, []byte{0x01, 0x01, 0x00}, []byte{0x01, 0x01, 0x00}},
+		{[]byte{0x11, 0x22, 0x00, 0x33}, []byte{0x03, 0x11, 0x22, 0x02, 0x33, 0x00}, []byte{0x03, 0x11, 0x22, 0x33, 0x00}},
+		{[]byte{0x11, 0x00, 0x02}, []byte{0x02, 0x11, 0x02, 0x02, 0x00}, []byte{0x02, 0x11, 0x02, 0x02, 0x00}},
+		{long, append(append([]byte{0xff}, long...), 0x01, 0x00), append(append([]byte{0xff}, long...), 0x01, 0x00)},
+	}
+
+	for _, test := range tests {
+		for _, c := range []struct {
+			codec  *Codec
+			expect []byte
+		}{
+			{COBS, test.cobs},
+			{COBSR, test.cobsr},
+		} {
+			frame := c.codec.Encode(test.payload)
+			if !bytes.Equal(frame, c.expect) {
+				t.Errorf("%s: encoding % X expected % X got % X", c.codec.Name, test.payload, c.expect, frame)
+			}
+			p, err := c.codec.Decode(frame)
+			if err != nil {
+				t.Errorf("%s: decoding % X got unexpected err: %s", c.codec.Name, frame, err)
+				continue
+			}
+			if b := p.PeekRemainingBytes(); !bytes.Equal(b, test.payload) {
+				t.Errorf("%s: decoding % X expected % X got % X", c.codec.Name, frame, test.payload, b)
+			}
+		}
+	}
+
+	_, err := COBS.Decode([]byte{0x05, 0x11, 0x00})
+	var e *decoder.OffsetError
+	if !errors.As(err, &e) || e.Offset != 0 || e.Err != ErrMalformedFrame {
+		t.Errorf("expected ErrMalformedFrame at 0 got %v", err)
+	}
+}
+
+func TestHDLC(t *testing.T) {
+	// PPP LCP configure request
+	payload := []byte{0xff, 0x03, 0xc0, 0x21, 0x01, 0x01, 0x00, 0x04, 0x7e}
+	if fcs := FCS16([]byte("123456789")); fcs != 0x906e {
+		t.Errorf("expected FCS 0x906E got %X", fcs)
+	}
+
+	frame := HDLC.Encode(payload)
+	if frame[0] != HDLCFlag || frame[len(frame)-1] != HDLCFlag {
+		t.Errorf("expected flags at both ends % X", frame)
+	}
+	if bytes.IndexByte(frame[1:len(frame)-1], HDLCFlag) != -1 {
+		t.Errorf("expected flag to be escaped % X", frame)
+	}
+	p, err := HDLC.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := p.PeekRemainingBytes(); !bytes.Equal(b, payload) {
+		t.Errorf("expected % X got % X", payload, b)
+	}
+
+	frame[2] ^= 0x01
+	_, err = HDLC.Decode(frame)
+	if !errors.Is(err, ErrBadFCS) {
+		t.Errorf("expected ErrBadFCS got %v", err)
+	}
+
+	_, err = HDLC.Decode([]byte{HDLCFlag, 0x01, 0x02, HDLCEscape, HDLCFlag})
+	var e *decoder.OffsetError
+	if !errors.As(err, &e) || e.Offset != 3 || e.Err != decoder.ErrInvalidEscape {
+		t.Errorf("expected ErrInvalidEscape at 3 got %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	var stream []byte
+	stream = append(stream, SLIP.Encode([]byte("one"))...)
+	stream = append(stream, 0x01, SLIPEsc, 0x02, SLIPEnd) // Bad escape at stream offset 6
+	stream = append(stream, SLIP.Encode([]byte("two"))...)
+	stream = append(stream, []byte("three")...) // No final END
+
+	r := NewReader(bytes.NewReader(stream), SLIP)
+	for _, test := range []struct {
+		expect string
+		offset int
+	}{
+		{"one", -1},
+		{"", 6},
+		{"two", -1},
+		{"three", -1},
+	} {
+		p, err := r.Next()
+		if test.offset >= 0 {
+			var e *decoder.OffsetError
+			if !errors.As(err, &e) || e.Offset != test.offset {
+				t.Errorf("expected error at %d got %v", test.offset, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("got unexpected err: %s", err)
+			continue
+		}
+		if s := string(p.PeekRemainingBytes()); s != test.expect {
+			t.Errorf("expected '%s' got '%s'", test.expect, s)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+
+	r = NewReader(bytes.NewReader(append(COBS.Encode(make([]byte, 100)), COBS.Encode([]byte{0x01})...)), COBS)
+	r.MaxFrameSize = 50
+	if _, err := r.Next(); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge got %v", err)
+	}
+	if p, err := r.Next(); err != nil || p.Byte() != 0x01 {
+		t.Errorf("expected to read the next frame got err %v", err)
+	}
+
+	// An over-size final frame without a delimiter
+	r = NewReader(bytes.NewReader(append([]byte{0x00, 0x02, 0x01, 0x00}, bytes.Repeat([]byte{0x01}, 100)...)), COBS)
+	r.MaxFrameSize = 50
+	if p, err := r.Next(); err != nil || p.Byte() != 0x01 {
+		t.Errorf("expected to read the first frame got err %v", err)
+	}
+	var e *decoder.OffsetError
+	if _, err := r.Next(); !errors.Is(err, ErrFrameTooLarge) || !errors.As(err, &e) || e.Offset != 4 {
+		t.Errorf("expected ErrFrameTooLarge at 4 got %v", err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package framing
+
+import (
+	"encoding/binary"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// HDLC special bytes
+const (
+	HDLCFlag   = 0x7e // Frame delimiter
+	HDLCEscape = 0x7d // Escape, the following byte is xor'd with 0x20
+)
+
+// HDLC is RFC 1662 HDLC-like async framing with a 16 bit FCS. Decode checks and removes the FCS,
+// Encode appends it and escapes the flag, escape and all control characters
+var HDLC = &Codec{
+	Name:      "HDLC",
+	Delimiter: HDLCFlag,
+	decode:    hdlcDecode,
+	encode:    hdlcEncode,
+}
+
+// goodFCS is the FCS of a frame including its own FCS
+const goodFCS = 0xf0b8
+
+// FCS16 returns the RFC 1662 16 bit frame check sequence (CRC-16/X.25) of the given bytes
+func FCS16(b []byte) uint16 {
+	return fcs16(0xffff, b) ^ 0xffff
+}
+
+func fcs16(fcs uint16, b []byte) uint16 {
+	for _, c := range b {
+		fcs ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if fcs&0x0001 != 0 {
+				fcs = fcs>>1 ^ 0x8408
+			} else {
+				fcs >>= 1
+			}
+		}
+	}
+	return fcs
+}
+
+func hdlcDecode(frame []byte) ([]byte, error) {
+	v := make([]byte, 0, len(frame))
+	for i := 0; i < len(frame); i++ {
+		c := frame[i]
+		if c == HDLCEscape {
+			if i+1 >= len(frame) {
+				return nil, &decoder.OffsetError{Offset: i, Err: decoder.ErrInvalidEscape}
+			}
+			i++
+			c = frame[i] ^ 0x20
+		}
+		v = append(v, c)
+	}
+	if len(v) < 2 {
+		return nil, &decoder.OffsetError{Offset: 0, Err: ErrMalformedFrame}
+	}
+	if fcs16(0xffff, v) != goodFCS {
+		return nil, &decoder.OffsetError{Offset: len(frame) - 2, Err: ErrBadFCS}
+	}
+	return v[:len(v)-2], nil
+}
+
+func hdlcEncode(b []byte) []byte {
+	var fcs [2]byte
+	binary.LittleEndian.PutUint16(fcs[:], FCS16(b))
+
+	v := make([]byte, 0, len(b)+6)
+	v = append(v, HDLCFlag)
+	for _, c := range append(b[:len(b):len(b)], fcs[:]...) {
+		if c == HDLCFlag || c == HDLCEscape || c < 0x20 {
+			v = append(v, HDLCEscape, c^0x20)
+			continue
+		}
+		v = append(v, c)
+	}
+	return append(v, HDLCFlag)
+}
@@ -0,0 +1,59 @@
+package framing
+
+import decoder "github.com/kgolding/go-decoder"
+
+// SLIP special bytes
+const (
+	SLIPEnd    = 0xc0 // Frame end
+	SLIPEsc    = 0xdb // Escape
+	SLIPEscEnd = 0xdc // Escaped frame end
+	SLIPEscEsc = 0xdd // Escaped escape
+)
+
+// SLIP is RFC 1055 Serial Line IP framing
+var SLIP = &Codec{
+	Name:      "SLIP",
+	Delimiter: SLIPEnd,
+	decode:    slipDecode,
+	encode:    slipEncode,
+}
+
+func slipDecode(frame []byte) ([]byte, error) {
+	v := make([]byte, 0, len(frame))
+	for i := 0; i < len(frame); i++ {
+		c := frame[i]
+		if c != SLIPEsc {
+			v = append(v, c)
+			continue
+		}
+		if i+1 >= len(frame) {
+			return nil, &decoder.OffsetError{Offset: i, Err: decoder.ErrInvalidEscape}
+		}
+		switch frame[i+1] {
+		case SLIPEscEnd:
+			v = append(v, SLIPEnd)
+		case SLIPEscEsc:
+			v = append(v, SLIPEsc)
+		default:
+			return nil, &decoder.OffsetError{Offset: i, Err: decoder.ErrInvalidEscape}
+		}
+		i++
+	}
+	return v, nil
+}
+
+func slipEncode(b []byte) []byte {
+	v := make([]byte, 0, len(b)+2)
+	v = append(v, SLIPEnd)
+	for _, c := range b {
+		switch c {
+		case SLIPEnd:
+			v = append(v, SLIPEsc, SLIPEscEnd)
+		case SLIPEsc:
+			v = append(v, SLIPEsc, SLIPEscEsc)
+		default:
+			v = append(v, c)
+		}
+	}
+	return append(v, SLIPEnd)
+}
@@ -0,0 +1,193 @@
+// Package hexfile parses Intel HEX and Motorola S-record firmware images using a *decoder.Packet, assembling
+// the records into a sparse memory map.
+package hexfile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrOverlap = errors.New("overlapping data")
+var ErrAddressRange = errors.New("address out of range")
+var ErrUnmapped = errors.New("address not mapped")
+
+// Segment is a contiguous block of memory
+type Segment struct {
+	Address uint32
+	Data    []byte
+}
+
+// End returns the address just past the segment, which is 1<<32 for a segment at the top of memory
+func (s Segment) End() uint64 {
+	return uint64(s.Address) + uint64(len(s.Data))
+}
+
+// Gap is a range of unmapped addresses between two segments
+type Gap struct {
+	Address uint32
+	Length  uint32
+}
+
+// Memory is a sparse memory map
+type Memory struct {
+	Segments []Segment // In address order, adjacent data being merged into one segment
+}
+
+// Add copies data into the memory at the given address. Data overlapping data already added
+// returns ErrOverlap
+func (m *Memory) Add(address uint32, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	end := uint64(address) + uint64(len(data))
+	if end > 1<<32 {
+		return fmt.Errorf("%w: 0x%08x", ErrAddressRange, address)
+	}
+	// The first segment ending after the address
+	i := sort.Search(len(m.Segments), func(i int) bool {
+		return m.Segments[i].End() > uint64(address)
+	})
+	if i < len(m.Segments) && uint64(m.Segments[i].Address) < end {
+		return fmt.Errorf("%w: 0x%08x", ErrOverlap, maxAddress(address, m.Segments[i].Address))
+	}
+
+	if i > 0 && m.Segments[i-1].End() == uint64(address) {
+		// Extend the previous segment, joining it with the next if the gap is now filled
+		prev := &m.Segments[i-1]
+		prev.Data = append(prev.Data, data...)
+		if i < len(m.Segments) && uint64(m.Segments[i].Address) == end {
+			prev.Data = append(prev.Data, m.Segments[i].Data...)
+			m.Segments = append(m.Segments[:i], m.Segments[i+1:]...)
+		}
+		return nil
+	}
+	if i < len(m.Segments) && uint64(m.Segments[i].Address) == end {
+		next := &m.Segments[i]
+		next.Data = append(append([]byte{}, data...), next.Data...)
+		next.Address = address
+		return nil
+	}
+	m.Segments = append(m.Segments, Segment{})
+	copy(m.Segments[i+1:], m.Segments[i:])
+	m.Segments[i] = Segment{Address: address, Data: append([]byte{}, data...)}
+	return nil
+}
+
+func maxAddress(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Size returns the number of mapped bytes
+func (m *Memory) Size() int {
+	n := 0
+	for _, s := range m.Segments {
+		n += len(s.Data)
+	}
+	return n
+}
+
+// Gaps returns the unmapped ranges between the first & last mapped addresses
+func (m *Memory) Gaps() []Gap {
+	var v []Gap
+	for i := 1; i < len(m.Segments); i++ {
+		start := m.Segments[i-1].End()
+		v = append(v, Gap{Address: uint32(start), Length: uint32(uint64(m.Segments[i].Address) - start)})
+	}
+	return v
+}
+
+// segment returns the index of the segment holding the address, or -1
+func (m *Memory) segment(address uint32) int {
+	i := sort.Search(len(m.Segments), func(i int) bool {
+		return m.Segments[i].End() > uint64(address)
+	})
+	if i < len(m.Segments) && m.Segments[i].Address <= address {
+		return i
+	}
+	return -1
+}
+
+// Start returns the lowest mapped address
+func (m *Memory) Start() uint32 {
+	if len(m.Segments) == 0 {
+		return 0
+	}
+	return m.Segments[0].Address
+}
+
+// Packet returns a packet over a copy of the whole memory map with unmapped bytes set to fill, offset 0 being
+// the lowest mapped address. Use Offset to find the offset of an absolute address for the Packet's *At
+// readers. Every byte from the lowest to the highest mapped address is copied, including the gaps
+func (m *Memory) Packet(fill byte) *decoder.Packet {
+	if len(m.Segments) == 0 {
+		return decoder.New(nil)
+	}
+	start := m.Start()
+	return decoder.New(m.Bytes(start, int(m.Segments[len(m.Segments)-1].End()-uint64(start)), fill))
+}
+
+// PacketAt returns the Packet with its internal pointer at the given mapped address, reads running on across
+// gaps into the following segments
+func (m *Memory) PacketAt(address uint32, fill byte) (*decoder.Packet, error) {
+	if m.segment(address) == -1 {
+		return nil, fmt.Errorf("%w: 0x%08x", ErrUnmapped, address)
+	}
+	p := m.Packet(fill)
+	p.SeekTo(m.Offset(address))
+	return p, nil
+}
+
+// Offset returns the offset of an absolute address in the data of the Packet
+func (m *Memory) Offset(address uint32) int {
+	return int(int64(address) - int64(m.Start()))
+}
+
+// Bytes returns a copy of the given number of bytes at the address, with unmapped bytes set to fill,
+// e.g. 0xff for erased flash
+func (m *Memory) Bytes(address uint32, length int, fill byte) []byte {
+	v := make([]byte, length)
+	for i := range v {
+		v[i] = fill
+	}
+	end := uint64(address) + uint64(length)
+	for _, s := range m.Segments {
+		if s.End() <= uint64(address) || uint64(s.Address) >= end {
+			continue
+		}
+		if s.Address >= address {
+			copy(v[s.Address-address:], s.Data)
+		} else {
+			copy(v, s.Data[address-s.Address:])
+		}
+	}
+	return v
+}
+
+// Image is a parsed firmware file
+type Image struct {
+	Memory
+	StartAddress uint32 // The execution start address, for Intel HEX segment starts this is CS * 16 + IP
+	HasStart     bool
+	Header       []byte // The S0 header record's data
+}
+
+// LineError records the line of a file at which an error occurred
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s on line %d", e.Err, e.Line)
+}
+
+// Unwrap returns the underlying error for use with errors.Is
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,101 @@
+package hexfile
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMemoryAdd(t *testing.T) {
+	var m Memory
+	steps := []struct {
+		address uint32
+		data    string
+	}{
+		{0x100, "cd"},
+		{0x104, "gh"},
+		{0x0fe, "ab"}, // Prepends to the first segment
+		{0x102, "ef"}, // Joins the two segments
+		{0x200, "xy"},
+	}
+	for _, s := range steps {
+		if err := m.Add(s.address, []byte(s.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(m.Segments) != 2 || m.Segments[0].Address != 0xfe || string(m.Segments[0].Data) != "abcdefgh" ||
+		m.Segments[1].Address != 0x200 || m.Size() != 10 {
+		t.Errorf("unexpected segments %+v", m.Segments)
+	}
+	gaps := m.Gaps()
+	if len(gaps) != 1 || gaps[0].Address != 0x106 || gaps[0].Length != 0xfa {
+		t.Errorf("unexpected gaps %+v", gaps)
+	}
+
+	if err := m.Add(0x1ff, []byte("zz")); !errors.Is(err, ErrOverlap) {
+		t.Errorf("expected ErrOverlap got %v", err)
+	}
+	if err := m.Add(0x100, []byte("c")); !errors.Is(err, ErrOverlap) {
+		t.Errorf("expected ErrOverlap got %v", err)
+	}
+	if err := m.Add(0xffffffff, []byte("ab")); !errors.Is(err, ErrAddressRange) {
+		t.Errorf("expected ErrAddressRange got %v", err)
+	}
+	if err := m.Add(0xffffffff, []byte("a")); err != nil || m.Segments[2].End() != 1<<32 {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestMemoryRead(t *testing.T) {
+	var m Memory
+	m.Add(0x08000000, []byte{0x01, 0x02, 0x03, 0x04})
+	m.Add(0x08000010, []byte{0xaa, 0xbb})
+
+	p, err := m.PacketAt(0x08000002, 0xff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := p.Uint16(); v != 0x0304 {
+		t.Errorf("expected 0x0304 got 0x%04x", v)
+	}
+	// Reads run on across the gap into the next segment
+	if v := p.Bytes(14); !bytes.Equal(v, append(bytes.Repeat([]byte{0xff}, 12), 0xaa, 0xbb)) {
+		t.Errorf("unexpected bytes % x", v)
+	}
+	if p.Byte(); p.Err == nil {
+		t.Error("expected error reading past the last segment")
+	}
+
+	// Absolute addresses through Offset
+	p = m.Packet(0)
+	if v := p.Uint16At(m.Offset(0x08000001)); v != 0x0203 {
+		t.Errorf("expected 0x0203 at 0x08000001 got 0x%04x", v)
+	}
+	if v := p.Uint32At(m.Offset(0x0800000e)); v != 0x0000aabb {
+		t.Errorf("expected 0x0000aabb at 0x0800000e got 0x%08x", v)
+	}
+	if err := p.SeekTo(m.Offset(0x08000011)); err != nil || p.Byte() != 0xbb {
+		t.Errorf("expected 0xbb at 0x08000011 err %v", err)
+	}
+	if m.Offset(0x07ffffff) != -1 {
+		t.Errorf("expected offset -1 got %d", m.Offset(0x07ffffff))
+	}
+
+	if _, err := m.PacketAt(0x08000004, 0xff); !errors.Is(err, ErrUnmapped) {
+		t.Errorf("expected ErrUnmapped got %v", err)
+	}
+	if _, err := m.PacketAt(0x07ffffff, 0xff); !errors.Is(err, ErrUnmapped) {
+		t.Errorf("expected ErrUnmapped got %v", err)
+	}
+	if p := (&Memory{}).Packet(0); p.RemainingLength() != 0 {
+		t.Error("expected an empty packet for empty memory")
+	}
+
+	want := []byte{0xff, 0x01, 0x02, 0x03, 0x04, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xaa}
+	if b := m.Bytes(0x07ffffff, 18, 0xff); !bytes.Equal(b, want) {
+		t.Errorf("expected % x got % x", want, b)
+	}
+	if b := m.Bytes(0x08000011, 2, 0); !bytes.Equal(b, []byte{0xbb, 0}) {
+		t.Errorf("unexpected bytes % x", b)
+	}
+}
@@ -0,0 +1,248 @@
+package hexfile
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformedRecord = errors.New("malformed record")
+var ErrChecksum = errors.New("checksum mismatch")
+var ErrMissingEnd = errors.New("missing end of file record")
+var ErrRecordCount = errors.New("record count mismatch")
+var ErrUnknownFormat = errors.New("unknown file format")
+
+// Intel HEX record types
+const (
+	ihexData                   = 0x00
+	ihexEndOfFile              = 0x01
+	ihexExtendedSegmentAddress = 0x02
+	ihexStartSegmentAddress    = 0x03
+	ihexExtendedLinearAddress  = 0x04
+	ihexStartLinearAddress     = 0x05
+)
+
+// Parse detects whether the file is Intel HEX or S-records from its first character, after any byte order
+// mark & leading whitespace, and parses it
+func Parse(r io.Reader) (*Image, error) {
+	br := bufio.NewReader(r)
+	skipped := 0 // Blank lines before the first record, counted as LineReader does
+	var prev rune
+	for n := 0; ; n++ {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return nil, &LineError{Line: skipped + 1, Err: ErrUnknownFormat}
+		}
+		switch {
+		case c == '\ufeff' && n == 0:
+		case c == '\r', c == '\n' && prev != '\r':
+			skipped++
+		case c == '\n', c == ' ', c == '\t':
+		case c == ':', c == 'S':
+			br.UnreadRune()
+			var img *Image
+			if c == ':' {
+				img, err = ParseIntelHex(br)
+			} else {
+				img, err = ParseSRecord(br)
+			}
+			if e, ok := err.(*LineError); ok {
+				e.Line += skipped
+			}
+			return img, err
+		default:
+			return nil, &LineError{Line: skipped + 1, Err: ErrUnknownFormat}
+		}
+		prev = c
+	}
+}
+
+// lines calls f with each non-blank line & its number, returning errors as a *LineError.
+// A UTF-8 byte order mark at the start of the first line is dropped
+func lines(r io.Reader, f func(line string) error) error {
+	lr := decoder.NewLineReader(r, decoder.DefaultLineConfig)
+	for n := 1; ; n++ {
+		line, err := lr.Line()
+		if err == io.EOF {
+			return nil
+		}
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if err == nil {
+			if line = strings.TrimSpace(line); line != "" {
+				err = f(line)
+			}
+		}
+		if err != nil {
+			return &LineError{Line: n, Err: err}
+		}
+	}
+}
+
+// record decodes the ASCII hex of a record following its start code, returning a packet of the bytes
+func record(p *decoder.Packet) (*decoder.Packet, error) {
+	start := p.Index()
+	if hex := p.StringHex(); hex == "" || len(hex) != len(p.PeekBytes())-start {
+		return nil, ErrMalformedRecord
+	}
+	p.SeekTo(start)
+	body := p.Decode(decoder.Hex, p.RemainingLength())
+	if p.Err != nil {
+		return nil, ErrMalformedRecord
+	}
+	return body, nil
+}
+
+// checksumTotal returns the low byte of the sum of all the bytes of a record
+func checksumTotal(p *decoder.Packet) byte {
+	var sum byte
+	for _, b := range p.PeekBytes() {
+		sum += b
+	}
+	return sum
+}
+
+// ParseIntelHex parses an Intel HEX file, validating each record's checksum. Errors are a *LineError,
+// other than ErrMissingEnd if there is no end of file record
+func ParseIntelHex(r io.Reader) (*Image, error) {
+	img := &Image{}
+	var base uint32
+	end := false
+	err := lines(r, func(line string) error {
+		p := decoder.New([]byte(line))
+		if !p.ExpectByte(':') || end {
+			return ErrMalformedRecord
+		}
+		body, err := record(p)
+		if err != nil {
+			return err
+		}
+		// Byte count, address, type, data & checksum
+		length := int(body.Byte())
+		offset := body.Uint16()
+		typ := body.Byte()
+		data := body.Bytes(length)
+		body.Byte()
+		if body.Err != nil || !body.EOF() {
+			return ErrMalformedRecord
+		}
+		if checksumTotal(body) != 0 {
+			return ErrChecksum
+		}
+
+		switch typ {
+		case ihexData:
+			return img.Add(base+uint32(offset), data)
+		case ihexEndOfFile:
+			end = true
+		case ihexExtendedSegmentAddress, ihexExtendedLinearAddress:
+			if length != 2 {
+				return ErrMalformedRecord
+			}
+			base = uint32(data[0])<<8 | uint32(data[1])
+			if typ == ihexExtendedSegmentAddress {
+				base <<= 4
+			} else {
+				base <<= 16
+			}
+		case ihexStartSegmentAddress, ihexStartLinearAddress:
+			if length != 4 {
+				return ErrMalformedRecord
+			}
+			v := decoder.New(data)
+			if typ == ihexStartSegmentAddress {
+				img.StartAddress = uint32(v.Uint16())<<4 + uint32(v.Uint16())
+			} else {
+				img.StartAddress = v.Uint32()
+			}
+			img.HasStart = true
+		default:
+			return ErrMalformedRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !end {
+		return nil, ErrMissingEnd
+	}
+	return img, nil
+}
+
+// ParseSRecord parses a Motorola S-record file, validating each record's checksum and any record count.
+// Errors are a *LineError
+func ParseSRecord(r io.Reader) (*Image, error) {
+	img := &Image{}
+	count := 0
+	end := false
+	err := lines(r, func(line string) error {
+		p := decoder.New([]byte(line))
+		if !p.ExpectByte('S') || end {
+			return ErrMalformedRecord
+		}
+		typ := p.Byte()
+		if p.Err != nil {
+			return ErrMalformedRecord
+		}
+		body, err := record(p)
+		if err != nil {
+			return err
+		}
+		// The byte count covers the address, data & checksum
+		length := int(body.Byte())
+		if body.Err != nil || length == 0 || length != body.RemainingLength() {
+			return ErrMalformedRecord
+		}
+		if checksumTotal(body) != 0xff {
+			return ErrChecksum
+		}
+
+		var address uint32
+		switch typ {
+		case '0', '1', '5', '9':
+			address = uint32(body.Uint16())
+		case '2', '6', '8':
+			address = body.Uint24()
+		case '3', '7':
+			address = body.Uint32()
+		default:
+			return ErrMalformedRecord
+		}
+		data := body.Bytes(body.RemainingLength() - 1)
+		if body.Err != nil {
+			return ErrMalformedRecord
+		}
+
+		switch typ {
+		case '0':
+			img.Header = data
+		case '1', '2', '3':
+			count++
+			return img.Add(address, data)
+		case '5', '6':
+			if len(data) != 0 {
+				return ErrMalformedRecord
+			}
+			if uint32(count) != address {
+				return ErrRecordCount
+			}
+		case '7', '8', '9':
+			if len(data) != 0 {
+				return ErrMalformedRecord
+			}
+			img.StartAddress = address
+			img.HasStart = true
+			end = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
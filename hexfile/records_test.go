@@ -0,0 +1,213 @@
+package hexfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// ihex returns an Intel HEX record with a valid checksum
+func ihex(typ byte, address uint16, data ...byte) string {
+	b := append([]byte{byte(len(data)), byte(address >> 8), byte(address), typ}, data...)
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return fmt.Sprintf(":%X%02X\n", b, -sum)
+}
+
+// srec returns an S-record with a valid checksum
+func srec(typ byte, address []byte, data ...byte) string {
+	b := append([]byte{byte(len(address) + len(data) + 1)}, address...)
+	b = append(b, data...)
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return fmt.Sprintf("S%c%X%02X\n", typ, b, ^sum)
+}
+
+func TestParseIntelHex(t *testing.T) {
+	file := `:10010000214601360121470136007EFE09D2190140
+:100110002146017E17C20001FF5F16002148011928
+:10012000194E79234623965778239EDA3F01B2CAA7
+:100130003F0156702B5E712B722B732146013421C7
+:00000001FF
+`
+	img, err := ParseIntelHex(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Segments) != 1 || img.Segments[0].Address != 0x100 || len(img.Segments[0].Data) != 64 || img.HasStart {
+		t.Fatalf("unexpected image %+v", img)
+	}
+	p, _ := img.PacketAt(0x13f, 0xff)
+	if v := p.Byte(); v != 0x21 {
+		t.Errorf("expected 0x21 got 0x%02x", v)
+	}
+}
+
+func TestParseIntelHexAddressing(t *testing.T) {
+	file := ihex(ihexExtendedLinearAddress, 0, 0x08, 0x00) +
+		ihex(ihexData, 0x0000, 1, 2, 3, 4) +
+		ihex(ihexData, 0x0100, 5, 6) +
+		ihex(ihexExtendedSegmentAddress, 0, 0x10, 0x00) +
+		ihex(ihexData, 0x0010, 7) +
+		ihex(ihexStartLinearAddress, 0, 0x08, 0x00, 0x01, 0x31) +
+		"\r\n" +
+		ihex(ihexEndOfFile, 0)
+	img, err := ParseIntelHex(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Segments) != 3 || img.Segments[0].Address != 0x00010010 || img.Segments[1].Address != 0x08000000 ||
+		img.Segments[2].Address != 0x08000100 {
+		t.Errorf("unexpected segments %+v", img.Segments)
+	}
+	if gaps := img.Gaps(); len(gaps) != 2 || gaps[1].Address != 0x08000004 || gaps[1].Length != 0xfc {
+		t.Errorf("unexpected gaps %+v", gaps)
+	}
+	if !img.HasStart || img.StartAddress != 0x08000131 {
+		t.Errorf("unexpected start address 0x%08x", img.StartAddress)
+	}
+
+	img, err = ParseIntelHex(strings.NewReader(ihex(ihexStartSegmentAddress, 0, 0x12, 0x34, 0x00, 0x10) + ihex(ihexEndOfFile, 0)))
+	if err != nil || img.StartAddress != 0x12350 {
+		t.Errorf("unexpected start address 0x%08x err %v", img.StartAddress, err)
+	}
+}
+
+func TestParseIntelHexErrors(t *testing.T) {
+	eof := ihex(ihexEndOfFile, 0)
+	tests := []struct {
+		name string
+		file string
+		err  error
+		line int
+	}{
+		{"checksum", ihex(ihexData, 0, 1) + ":0100000001FF\n" + eof, ErrChecksum, 2},
+		{"missing end", ihex(ihexData, 0, 1), ErrMissingEnd, 0},
+		{"odd length", ":0100000001FE0\n" + eof, ErrMalformedRecord, 1},
+		{"not hex", ":01000000G1FE\n" + eof, ErrMalformedRecord, 1},
+		{"byte count", ":0200000001FD\n" + eof, ErrMalformedRecord, 1},
+		{"start code", "01000000010E\n", ErrMalformedRecord, 1},
+		{"after end", eof + ihex(ihexData, 0, 1), ErrMalformedRecord, 2},
+		{"overlap", ihex(ihexData, 0, 1, 2) + ihex(ihexData, 1, 3) + eof, ErrOverlap, 2},
+		{"record type", ihex(6, 0) + eof, ErrMalformedRecord, 1},
+		{"extended address length", ihex(ihexExtendedLinearAddress, 0, 1) + eof, ErrMalformedRecord, 1},
+	}
+	for _, test := range tests {
+		_, err := ParseIntelHex(strings.NewReader(test.file))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v got %v", test.name, test.err, err)
+			continue
+		}
+		var le *LineError
+		if errors.As(err, &le) != (test.line != 0) || (le != nil && le.Line != test.line) {
+			t.Errorf("%s: expected line %d got %v", test.name, test.line, err)
+		}
+	}
+}
+
+func TestParseSRecord(t *testing.T) {
+	file := `S00F000068656C6C6F202020202000003C
+S11F00007C0802A6900100049421FFF07C6C1B787C8C23783C6000003863000026
+S11F001C4BFFFFE5398000007D83637880010014382100107C0803A64E800020E9
+S111003848656C6C6F20776F726C642E0A0042
+S5030003F9
+S9030000FC
+`
+	img, err := ParseSRecord(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(img.Header) != "hello     \x00\x00" || len(img.Segments) != 1 || len(img.Segments[0].Data) != 70 ||
+		!img.HasStart || img.StartAddress != 0 {
+		t.Fatalf("unexpected image %+v", img)
+	}
+	p, _ := img.PacketAt(0x38, 0xff)
+	if s := p.StringZeroPadded(12); s != "Hello world." {
+		t.Errorf("expected Hello world. got %q", s)
+	}
+
+	file = srec('2', []byte{0x01, 0x00, 0x00}, 1, 2) +
+		srec('3', []byte{0x08, 0x00, 0x00, 0x00}, 3, 4) +
+		srec('7', []byte{0x08, 0x00, 0x00, 0x00})
+	img, err = ParseSRecord(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Segments) != 2 || img.Segments[0].Address != 0x10000 || img.Segments[1].Address != 0x08000000 ||
+		img.StartAddress != 0x08000000 {
+		t.Errorf("unexpected image %+v", img)
+	}
+}
+
+func TestParseSRecordErrors(t *testing.T) {
+	data := srec('1', []byte{0, 0}, 1)
+	tests := []struct {
+		name string
+		file string
+		err  error
+		line int
+	}{
+		{"checksum", data + "S1040001010A\n", ErrChecksum, 2},
+		{"count", data + srec('5', []byte{0, 2}), ErrRecordCount, 2},
+		{"byte count", "S1050000010A\n", ErrMalformedRecord, 1},
+		{"type", srec('4', []byte{0, 0}), ErrMalformedRecord, 1},
+		{"no type", "S\n", ErrMalformedRecord, 1},
+		{"after end", srec('9', []byte{0, 0}) + data, ErrMalformedRecord, 2},
+		{"overlap", data + data, ErrOverlap, 2},
+	}
+	for _, test := range tests {
+		_, err := ParseSRecord(strings.NewReader(test.file))
+		var le *LineError
+		if !errors.Is(err, test.err) || !errors.As(err, &le) || le.Line != test.line {
+			t.Errorf("%s: expected %v on line %d got %v", test.name, test.err, test.line, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	img, err := Parse(strings.NewReader(ihex(ihexData, 0x10, 1) + ihex(ihexEndOfFile, 0)))
+	if err != nil || img.Segments[0].Address != 0x10 {
+		t.Errorf("unexpected image %+v err %v", img, err)
+	}
+	img, err = Parse(strings.NewReader(srec('1', []byte{0, 0x20}, 1)))
+	if err != nil || img.Segments[0].Address != 0x20 {
+		t.Errorf("unexpected image %+v err %v", img, err)
+	}
+	if _, err := Parse(strings.NewReader("hello")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat got %v", err)
+	}
+
+	// Leading whitespace & a byte order mark are skipped
+	if _, err := Parse(strings.NewReader("\n:00000001FF\n")); err != nil {
+		t.Errorf("unexpected err %v", err)
+	}
+	img, err = Parse(strings.NewReader("\ufeff" + ihex(ihexData, 0x10, 1) + ihex(ihexEndOfFile, 0)))
+	if err != nil || img.Segments[0].Address != 0x10 {
+		t.Errorf("unexpected image %+v err %v", img, err)
+	}
+	img, err = Parse(strings.NewReader("\ufeff \r\n" + srec('1', []byte{0, 0x20}, 1)))
+	if err != nil || img.Segments[0].Address != 0x20 {
+		t.Errorf("unexpected image %+v err %v", img, err)
+	}
+	var le *LineError
+	if _, err := Parse(strings.NewReader("\n\n:00000001FE\n")); !errors.As(err, &le) || le.Line != 3 {
+		t.Errorf("expected error on line 3 got %v", err)
+	}
+	if _, err := Parse(strings.NewReader("\r\n\r\n\r:00000001FE\n")); !errors.As(err, &le) || le.Line != 4 {
+		t.Errorf("expected error on line 4 got %v", err)
+	}
+	if _, err := Parse(strings.NewReader(strings.Repeat(" ", 5000) + "\n:00000001FF\n")); err != nil {
+		t.Errorf("unexpected err after long whitespace %v", err)
+	}
+	if _, err := Parse(strings.NewReader("\n\nx")); !errors.As(err, &le) || le.Line != 3 {
+		t.Errorf("expected ErrUnknownFormat on line 3 got %v", err)
+	}
+	if _, err := Parse(strings.NewReader("\ufeff\n")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package decoder
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrDuplicateKey = errors.New("duplicate key")
+var ErrMalformedEntry = errors.New("malformed entry")
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyValue is a single KEY=VALUE pair
+type KeyValue struct {
+	Key    string
+	Value  string
+	Offset int // The offset of the entry in the data
+}
+
+// KeyValues is an ordered list of pairs as read by Packet.KeyValues
+type KeyValues []KeyValue
+
+// KeyValues reads the remaining data as entries separated by entrySep, each holding a key and value
+// separated by pairSep, e.g. "A=1;B=2" with "=" & ";" or "A:1\r\nB:2\r\n" with ":" & "\r\n".
+// Spaces & tabs around keys and values are trimmed and empty entries are skipped. Entries without a
+// pairSep or key, and repeated keys, set Err to an *OffsetError wrapping ErrMalformedEntry or
+// ErrDuplicateKey, leaving the internal pointer at that entry and returning the pairs read so far
+func (p *Packet) KeyValues(pairSep, entrySep []byte) KeyValues {
+	var v KeyValues
+	for p.idx < p.length {
+		entry := p.buf[p.idx:p.length]
+		next := len(entry)
+		if i := bytes.Index(entry, entrySep); i != -1 && len(entrySep) > 0 {
+			entry = entry[:i]
+			next = i + len(entrySep)
+		}
+		if len(trimSpace(entry)) == 0 {
+			p.idx += next
+			continue
+		}
+
+		i := bytes.Index(entry, pairSep)
+		if i == -1 || len(pairSep) == 0 || len(trimSpace(entry[:i])) == 0 {
+			p.Err = &OffsetError{Offset: p.idx, Err: ErrMalformedEntry}
+			return v
+		}
+		key := p.text(trimSpace(entry[:i]))
+		if _, ok := v.Get(key); ok {
+			p.Err = &OffsetError{Offset: p.idx, Err: ErrDuplicateKey}
+			return v
+		}
+		v = append(v, KeyValue{
+			Key:    key,
+			Value:  p.text(trimSpace(entry[i+len(pairSep):])),
+			Offset: p.idx,
+		})
+		p.idx += next
+	}
+	return v
+}
+
+// Get returns the value of the given key
+func (kv KeyValues) Get(key string) (string, bool) {
+	if i := kv.index(key); i != -1 {
+		return kv[i].Value, true
+	}
+	return "", false
+}
+
+// Int returns the value of the given key as an int, parsed as per AsciiInt.
+// Errors other than ErrKeyNotFound are an *OffsetError with the offset of the entry
+func (kv KeyValues) Int(key string) (int, error) {
+	i := kv.index(key)
+	if i == -1 {
+		return 0, ErrKeyNotFound
+	}
+	p := New([]byte(kv[i].Value))
+	n := p.AsciiInt()
+	if err := kv.checkNumber(i, p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Uint returns the value of the given key as a uint, parsed as per AsciiUInt.
+// Errors other than ErrKeyNotFound are an *OffsetError with the offset of the entry
+func (kv KeyValues) Uint(key string) (uint, error) {
+	i := kv.index(key)
+	if i == -1 {
+		return 0, ErrKeyNotFound
+	}
+	p := New([]byte(kv[i].Value))
+	n := p.AsciiUInt()
+	if err := kv.checkNumber(i, p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// checkNumber checks the whole value was read as a number
+func (kv KeyValues) checkNumber(i int, p *Packet) error {
+	if p.Err != nil {
+		return &OffsetError{Offset: kv[i].Offset, Err: p.Err}
+	}
+	if !p.EOF() {
+		return &OffsetError{Offset: kv[i].Offset, Err: ErrMalformedEntry}
+	}
+	return nil
+}
+
+func (kv KeyValues) index(key string) int {
+	for i := range kv {
+		if kv[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func trimSpace(b []byte) []byte {
+	return bytes.Trim(b, " \t")
+}
@@ -0,0 +1,84 @@
+package decoder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestKeyValues(t *testing.T) {
+	p := New([]byte("TEMP=-12;RH=45;;ID= dev1 ;"))
+	kv := p.KeyValues([]byte("="), []byte(";"))
+	if p.Err != nil {
+		t.Fatalf("got unexpected err: %s", p.Err)
+	}
+	expect := KeyValues{
+		{"TEMP", "-12", 0},
+		{"RH", "45", 9},
+		{"ID", "dev1", 16},
+	}
+	if !reflect.DeepEqual(kv, expect) {
+		t.Errorf("expected %+v got %+v", expect, kv)
+	}
+
+	if v, err := kv.Int("TEMP"); v != -12 || err != nil {
+		t.Errorf("expected -12 got %d err %v", v, err)
+	}
+	if v, err := kv.Uint("RH"); v != 45 || err != nil {
+		t.Errorf("expected 45 got %d err %v", v, err)
+	}
+	if _, err := kv.Uint("TEMP"); !errors.Is(err, ErrReadNoData) {
+		t.Errorf("expected ErrReadNoData got %v", err)
+	}
+	var e *OffsetError
+	if _, err := kv.Int("ID"); !errors.As(err, &e) || e.Offset != 16 {
+		t.Errorf("expected error at offset 16 got %v", err)
+	}
+	if _, err := kv.Int("NONE"); err != ErrKeyNotFound {
+		t.Errorf("expected ErrKeyNotFound got %v", err)
+	}
+	if v, ok := kv.Get("ID"); v != "dev1" || !ok {
+		t.Errorf("expected dev1 got %s", v)
+	}
+}
+
+func TestKeyValuesLines(t *testing.T) {
+	p := New([]byte("VOLT: 230\r\nFREQ: 50\r\n"))
+	kv := p.KeyValues([]byte(":"), []byte("\r\n"))
+	if p.Err != nil {
+		t.Fatalf("got unexpected err: %s", p.Err)
+	}
+	if v, err := kv.Int("FREQ"); v != 50 || err != nil {
+		t.Errorf("expected 50 got %d err %v", v, err)
+	}
+	if len(kv) != 2 {
+		t.Errorf("expected 2 pairs got %d", len(kv))
+	}
+}
+
+func TestKeyValuesErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		pairs  int
+		offset int
+		err    error
+	}{
+		{"A=1;B;C=3", 1, 4, ErrMalformedEntry},
+		{"A=1;=2", 1, 4, ErrMalformedEntry},
+		{"A=1;B=2;A=3", 2, 8, ErrDuplicateKey},
+	}
+	for _, test := range tests {
+		p := New([]byte(test.input))
+		kv := p.KeyValues([]byte("="), []byte(";"))
+		var e *OffsetError
+		if !errors.As(p.Err, &e) || e.Offset != test.offset || e.Err != test.err {
+			t.Errorf("with %s expected %v at %d got %v", test.input, test.err, test.offset, p.Err)
+		}
+		if len(kv) != test.pairs {
+			t.Errorf("with %s expected %d pairs got %d", test.input, test.pairs, len(kv))
+		}
+		if p.Index() != test.offset {
+			t.Errorf("with %s expected index %d got %d", test.input, test.offset, p.Index())
+		}
+	}
+}
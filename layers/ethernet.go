@@ -0,0 +1,55 @@
+package layers
+
+import (
+	"net"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// Ethernet is an Ethernet II header with any 802.1Q/802.1ad VLAN tags
+type Ethernet struct {
+	Dst       net.HardwareAddr
+	Src       net.HardwareAddr
+	VLANs     []VLAN    // Outermost first
+	EtherType EtherType // The payload's type, or its length for an 802.3 frame
+}
+
+// VLAN is an 802.1Q tag
+type VLAN struct {
+	TPID         EtherType
+	Priority     uint8
+	DropEligible bool
+	ID           uint16
+}
+
+// DecodeEthernet reads an Ethernet header and returns it with the rest of the frame as the payload. An 802.3
+// frame, where the EtherType is a length of less than 0x600, has its payload limited to that length.
+// The packet is set to big endian, errors are an *decoder.OffsetError and leave the packet unchanged
+func DecodeEthernet(p *decoder.Packet) (Ethernet, *decoder.Packet, error) {
+	m, start := p.Mark(), p.Index()
+	p.SetBigEndian()
+	var e Ethernet
+	e.Dst = net.HardwareAddr(p.Bytes(6))
+	e.Src = net.HardwareAddr(p.Bytes(6))
+	e.EtherType = EtherType(p.Uint16())
+	for e.EtherType == EtherTypeVLAN || e.EtherType == EtherTypeQinQ {
+		v := VLAN{TPID: e.EtherType}
+		v.Priority = uint8(p.Bits(3))
+		v.DropEligible = p.Bits(1) == 1
+		v.ID = uint16(p.Bits(12))
+		e.VLANs = append(e.VLANs, v)
+		e.EtherType = EtherType(p.Uint16())
+	}
+	if p.Err != nil {
+		return Ethernet{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+
+	length := p.RemainingLength()
+	if e.EtherType < 0x600 {
+		if int(e.EtherType) > length {
+			return Ethernet{}, nil, fail(p, m, p.Index()-2, ErrInvalidHeader)
+		}
+		length = int(e.EtherType)
+	}
+	return e, p.Sub(length), nil
+}
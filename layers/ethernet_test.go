@@ -0,0 +1,63 @@
+package layers
+
+import (
+	"errors"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestDecodeEthernet(t *testing.T) {
+	p := decoder.New(mustHex("ffffffffffff" + "001122334455" + "0800" + "45000014"))
+	e, payload, err := DecodeEthernet(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Dst.String() != "ff:ff:ff:ff:ff:ff" || e.Src.String() != "00:11:22:33:44:55" || e.EtherType != EtherTypeIPv4 || len(e.VLANs) != 0 {
+		t.Errorf("unexpected header %+v", e)
+	}
+	if payload.RemainingLength() != 4 || payload.Uint16() != 0x4500 || !p.EOF() {
+		t.Errorf("unexpected payload %v", payload.PeekBytes())
+	}
+}
+
+func TestDecodeEthernetVLAN(t *testing.T) {
+	// 802.1ad outer tag with VLAN 100, 802.1Q inner tag with priority 5, DEI & VLAN 4094
+	p := decoder.New(mustHex("ffffffffffff" + "001122334455" + "88a8" + "0064" + "8100" + "bffe" + "86dd" + "60"))
+	e, payload, err := DecodeEthernet(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.VLANs) != 2 || e.EtherType != EtherTypeIPv6 || payload.RemainingLength() != 1 {
+		t.Fatalf("unexpected header %+v", e)
+	}
+	if v := e.VLANs[0]; v.TPID != EtherTypeQinQ || v.ID != 100 || v.Priority != 0 || v.DropEligible {
+		t.Errorf("unexpected outer tag %+v", v)
+	}
+	if v := e.VLANs[1]; v.TPID != EtherTypeVLAN || v.ID != 4094 || v.Priority != 5 || !v.DropEligible {
+		t.Errorf("unexpected inner tag %+v", v)
+	}
+}
+
+func TestDecodeEthernet8023(t *testing.T) {
+	// An 802.3 length of 3, followed by padding
+	p := decoder.New(mustHex("0180c2000000" + "001122334455" + "0003" + "424203" + "000000"))
+	e, payload, err := DecodeEthernet(p)
+	if err != nil || e.EtherType != 3 || payload.RemainingLength() != 3 {
+		t.Errorf("unexpected header %+v err %v", e, err)
+	}
+
+	p = decoder.New(mustHex("0180c2000000" + "001122334455" + "0010" + "424203"))
+	var oe *decoder.OffsetError
+	if _, _, err := DecodeEthernet(p); !errors.Is(err, ErrInvalidHeader) || !errors.As(err, &oe) || oe.Offset != 12 {
+		t.Errorf("expected ErrInvalidHeader at 12 got %v", err)
+	}
+	if p.Index() != 0 {
+		t.Errorf("expected packet restored got index %d", p.Index())
+	}
+
+	p = decoder.New(mustHex("ffffffffffff" + "001122334455" + "8100" + "00"))
+	if _, _, err := DecodeEthernet(p); !errors.Is(err, decoder.ErrReadPastEndData) || p.Index() != 0 {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package layers
+
+import (
+	"net"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// IPv4 flags
+const (
+	IPv4DontFragment  = 0x2
+	IPv4MoreFragments = 0x1
+)
+
+// IPv4 is an IPv4 header
+type IPv4 struct {
+	IHL            uint8 // The header length in 32 bit words
+	DSCP           uint8
+	ECN            uint8
+	Length         uint16 // The total length of the header & payload
+	ID             uint16
+	Flags          uint8
+	FragmentOffset uint16 // In 8 byte units
+	TTL            uint8
+	Protocol       Protocol
+	Checksum       uint16
+	Src            net.IP
+	Dst            net.IP
+	Options        []Option
+}
+
+// IsFragment returns true if the payload is part of a fragmented datagram
+func (h IPv4) IsFragment() bool {
+	return h.Flags&IPv4MoreFragments != 0 || h.FragmentOffset != 0
+}
+
+// DecodeIPv4 reads an IPv4 header and returns it with its payload, which excludes any link layer padding.
+// If the header checksum is wrong the header & payload are returned with ErrChecksum.
+// The packet is set to big endian, other errors are an *decoder.OffsetError and leave the packet unchanged
+func DecodeIPv4(p *decoder.Packet) (IPv4, *decoder.Packet, error) {
+	m, start := p.Mark(), p.Index()
+	p.SetBigEndian()
+	var h IPv4
+	version := p.Bits(4)
+	h.IHL = uint8(p.Bits(4))
+	h.DSCP = uint8(p.Bits(6))
+	h.ECN = uint8(p.Bits(2))
+	h.Length = p.Uint16()
+	h.ID = p.Uint16()
+	h.Flags = uint8(p.Bits(3))
+	h.FragmentOffset = uint16(p.Bits(13))
+	h.TTL = p.Byte()
+	h.Protocol = Protocol(p.Byte())
+	h.Checksum = p.Uint16()
+	h.Src = net.IP(p.Bytes(4))
+	h.Dst = net.IP(p.Bytes(4))
+	if p.Err != nil {
+		return IPv4{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+	headerLength := int(h.IHL) * 4
+	if version != 4 || headerLength < 20 || int(h.Length) < headerLength {
+		return IPv4{}, nil, fail(p, m, start, ErrInvalidHeader)
+	}
+	if int(h.Length) > p.Index()-start+p.RemainingLength() {
+		return IPv4{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+
+	options := p.Sub(headerLength - 20)
+	if options == nil {
+		return IPv4{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+	var err error
+	if h.Options, err = readOptions(options, start+20); err != nil {
+		p.Restore(m)
+		return IPv4{}, nil, err
+	}
+	payload := p.Sub(int(h.Length) - headerLength)
+	if Checksum(p.PeekBytes()[start:start+headerLength]) != 0 {
+		return h, payload, &decoder.OffsetError{Offset: start + 10, Err: ErrChecksum}
+	}
+	return h, payload, nil
+}
+
+// IPv6 is an IPv6 header with its extension headers
+type IPv6 struct {
+	TrafficClass uint8
+	FlowLabel    uint32
+	Length       uint16 // The payload length, including extension headers
+	NextHeader   Protocol
+	HopLimit     uint8
+	Src          net.IP
+	Dst          net.IP
+	Extensions   []Extension
+	Protocol     Protocol      // The protocol of the payload after the extension headers
+	Fragment     *IPv6Fragment // Set if there is a fragment extension header
+}
+
+// Extension is an IPv6 extension header
+type Extension struct {
+	Type Protocol
+	Data []byte // Excluding the next header & length bytes, aliases the packet's buffer
+}
+
+// IPv6Fragment is an IPv6 fragment extension header
+type IPv6Fragment struct {
+	Offset uint16 // In 8 byte units
+	More   bool
+	ID     uint32
+}
+
+// IsFragment returns true if the payload is part of a fragmented packet
+func (h IPv6) IsFragment() bool {
+	return h.Fragment != nil && (h.Fragment.More || h.Fragment.Offset != 0)
+}
+
+// DecodeIPv6 reads an IPv6 header and any hop-by-hop, routing, fragment, authentication & destination
+// options extension headers, and returns it with the payload following them. The packet is set to big endian,
+// errors are an *decoder.OffsetError and leave the packet unchanged
+func DecodeIPv6(p *decoder.Packet) (IPv6, *decoder.Packet, error) {
+	m, start := p.Mark(), p.Index()
+	p.SetBigEndian()
+	var h IPv6
+	version := p.Bits(4)
+	h.TrafficClass = uint8(p.Bits(8))
+	h.FlowLabel = uint32(p.Bits(20))
+	h.Length = p.Uint16()
+	h.NextHeader = Protocol(p.Byte())
+	h.HopLimit = p.Byte()
+	h.Src = net.IP(p.Bytes(16))
+	h.Dst = net.IP(p.Bytes(16))
+	if p.Err != nil {
+		return IPv6{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+	if version != 6 {
+		return IPv6{}, nil, fail(p, m, start, ErrInvalidHeader)
+	}
+	if int(h.Length) > p.RemainingLength() {
+		return IPv6{}, nil, fail(p, m, start+4, decoder.ErrReadPastEndData)
+	}
+
+	payload := p.Sub(int(h.Length))
+	h.Protocol = h.NextHeader
+	for {
+		offset := start + 40 + payload.Index()
+		switch h.Protocol {
+		case ProtocolHopByHop, ProtocolRouting, ProtocolDestOpts, ProtocolAuth, ProtocolFragment:
+		default:
+			return h, payload, nil
+		}
+		hdr, err := payload.PeekN(2)
+		if err != nil {
+			return IPv6{}, nil, fail(p, m, offset, ErrInvalidHeader)
+		}
+		length := int(hdr[1])*8 + 8
+		switch h.Protocol {
+		case ProtocolAuth:
+			length = int(hdr[1])*4 + 8
+		case ProtocolFragment:
+			length = 8
+		}
+		b := payload.Bytes(length)
+		if payload.Err != nil {
+			return IPv6{}, nil, fail(p, m, offset, ErrInvalidHeader)
+		}
+		h.Extensions = append(h.Extensions, Extension{Type: h.Protocol, Data: b[2:]})
+		if h.Protocol == ProtocolFragment {
+			ext := decoder.New(b[2:])
+			h.Fragment = &IPv6Fragment{}
+			h.Fragment.Offset = uint16(ext.Bits(13))
+			ext.Bits(2) // Reserved
+			h.Fragment.More = ext.Bits(1) == 1
+			h.Fragment.ID = ext.Uint32()
+		}
+		h.Protocol = Protocol(b[0])
+	}
+}
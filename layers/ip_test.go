@@ -0,0 +1,146 @@
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// withChecksum sets the IPv4 header checksum of b
+func withChecksum(b []byte) []byte {
+	headerLength := int(b[0]&0x0f) * 4
+	binary.BigEndian.PutUint16(b[10:], 0)
+	binary.BigEndian.PutUint16(b[10:], Checksum(b[:headerLength]))
+	return b
+}
+
+func TestDecodeIPv4(t *testing.T) {
+	// IHL of 6 with a router alert option, DSCP 46, ECN 1, DF, followed by a payload & link layer padding
+	b := withChecksum(mustHex("46b9001c" + "abcd" + "4000" + "4011" + "0000" + "c0a80001" + "c0a800c7" +
+		"94040000" + "0102030405060708" + "0000"))
+	p := decoder.New(b)
+	h, payload, err := DecodeIPv4(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.IHL != 6 || h.DSCP != 46 || h.ECN != 1 || h.Length != 28 || h.ID != 0xabcd || h.Flags != IPv4DontFragment ||
+		h.FragmentOffset != 0 || h.TTL != 64 || h.Protocol != ProtocolUDP || h.IsFragment() {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if !h.Src.Equal(net.IPv4(192, 168, 0, 1)) || !h.Dst.Equal(net.IPv4(192, 168, 0, 199)) {
+		t.Errorf("unexpected addresses %v %v", h.Src, h.Dst)
+	}
+	if len(h.Options) != 1 || h.Options[0].Type != 0x94 || len(h.Options[0].Data) != 2 {
+		t.Errorf("unexpected options %+v", h.Options)
+	}
+	if payload.RemainingLength() != 4 || payload.Uint32() != 0x01020304 {
+		t.Errorf("unexpected payload %v", payload.PeekBytes())
+	}
+	if p.Index() != 28 {
+		t.Errorf("expected padding to be left unread got index %d", p.Index())
+	}
+
+	// A bad checksum still returns the header & payload
+	b[11]++
+	h, payload, err = DecodeIPv4(decoder.New(b))
+	if !errors.Is(err, ErrChecksum) || h.Protocol != ProtocolUDP || payload == nil {
+		t.Errorf("expected ErrChecksum with header got %+v err %v", h, err)
+	}
+}
+
+func TestDecodeIPv4Fragment(t *testing.T) {
+	b := withChecksum(mustHex("45000018" + "0001" + "2005" + "4011" + "0000" + "0a000001" + "0a000002" + "01020304"))
+	h, _, err := DecodeIPv4(decoder.New(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Flags != IPv4MoreFragments || h.FragmentOffset != 5 || !h.IsFragment() {
+		t.Errorf("unexpected header %+v", h)
+	}
+}
+
+func TestDecodeIPv4Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		hex    string
+		err    error
+		offset int
+	}{
+		{"short", "4500001400010000", decoder.ErrReadPastEndData, 0},
+		{"version", "650000140001000040110000" + "c0a80001c0a800c7", ErrInvalidHeader, 0},
+		{"IHL", "440000140001000040110000" + "c0a80001c0a800c7", ErrInvalidHeader, 0},
+		{"length", "450000200001000040110000" + "c0a80001c0a800c7", decoder.ErrReadPastEndData, 0},
+		{"option", "460000180001000040110000" + "c0a80001c0a800c7" + "01940500", ErrInvalidHeader, 21},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		_, _, err := DecodeIPv4(p)
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("%s: expected %v at %d got %v", test.name, test.err, test.offset, err)
+		}
+		if p.Index() != 0 || p.Err != nil {
+			t.Errorf("%s: expected packet restored got index %d err %v", test.name, p.Index(), p.Err)
+		}
+	}
+}
+
+func TestDecodeIPv6(t *testing.T) {
+	// Hop-by-hop options, a fragment header (offset 0, more fragments) and a 4 byte UDP payload
+	b := mustHex("6abcdef1" + "0014" + "00" + "40" +
+		"20010db8000000000000000000000001" + "20010db8000000000000000000000002" +
+		"2c" + "00" + "050200000100" + // Hop-by-hop with a router alert option
+		"11" + "00" + "0001" + "12345678" + // Fragment
+		"01020304")
+	p := decoder.New(b)
+	h, payload, err := DecodeIPv6(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.TrafficClass != 0xab || h.FlowLabel != 0xcdef1 || h.Length != 20 || h.NextHeader != ProtocolHopByHop ||
+		h.HopLimit != 64 || h.Protocol != ProtocolUDP {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if h.Src.String() != "2001:db8::1" || h.Dst.String() != "2001:db8::2" {
+		t.Errorf("unexpected addresses %v %v", h.Src, h.Dst)
+	}
+	if len(h.Extensions) != 2 || h.Extensions[0].Type != ProtocolHopByHop || len(h.Extensions[0].Data) != 6 ||
+		h.Extensions[1].Type != ProtocolFragment {
+		t.Errorf("unexpected extensions %+v", h.Extensions)
+	}
+	if f := h.Fragment; f == nil || f.Offset != 0 || !f.More || f.ID != 0x12345678 || !h.IsFragment() {
+		t.Errorf("unexpected fragment %+v", f)
+	}
+	if payload.RemainingLength() != 4 || payload.Uint32() != 0x01020304 || !p.EOF() {
+		t.Errorf("unexpected payload %v", payload.PeekBytes())
+	}
+}
+
+func TestDecodeIPv6Errors(t *testing.T) {
+	header := "60000000" + "0008" + "00" + "40" + "20010db8000000000000000000000001" + "20010db8000000000000000000000002"
+	tests := []struct {
+		name   string
+		hex    string
+		err    error
+		offset int
+	}{
+		{"short", header[:60], decoder.ErrReadPastEndData, 0},
+		{"version", "4" + header[1:] + "1100000000000000", ErrInvalidHeader, 0},
+		{"length", header + "1100", decoder.ErrReadPastEndData, 4},
+		{"extension", header + "1101000000000000", ErrInvalidHeader, 40},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		_, _, err := DecodeIPv6(p)
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("%s: expected %v at %d got %v", test.name, test.err, test.offset, err)
+		}
+		if p.Index() != 0 || p.Err != nil {
+			t.Errorf("%s: expected packet restored got index %d err %v", test.name, p.Index(), p.Err)
+		}
+	}
+}
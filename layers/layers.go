@@ -0,0 +1,134 @@
+// Package layers decodes Ethernet, IPv4, IPv6, UDP & TCP headers using a *decoder.Packet. Each decoder reads
+// its header from the packet's current position and returns the header with its payload as a sub-packet, ready
+// for the next layer's decoder.
+package layers
+
+import (
+	"errors"
+	"fmt"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrInvalidHeader = errors.New("invalid header")
+var ErrChecksum = errors.New("checksum mismatch")
+
+// EtherType is the protocol of an Ethernet frame's payload
+type EtherType uint16
+
+// EtherTypes
+const (
+	EtherTypeIPv4 EtherType = 0x0800
+	EtherTypeARP  EtherType = 0x0806
+	EtherTypeVLAN EtherType = 0x8100 // 802.1Q
+	EtherTypeIPv6 EtherType = 0x86dd
+	EtherTypeQinQ EtherType = 0x88a8 // 802.1ad
+)
+
+func (t EtherType) String() string {
+	switch t {
+	case EtherTypeIPv4:
+		return "IPv4"
+	case EtherTypeARP:
+		return "ARP"
+	case EtherTypeVLAN:
+		return "802.1Q"
+	case EtherTypeIPv6:
+		return "IPv6"
+	case EtherTypeQinQ:
+		return "802.1ad"
+	}
+	return fmt.Sprintf("0x%04x", uint16(t))
+}
+
+// Protocol is an IP protocol number, also used for IPv6 extension headers
+type Protocol uint8
+
+// IP protocols & IPv6 extension headers
+const (
+	ProtocolHopByHop Protocol = 0
+	ProtocolICMP     Protocol = 1
+	ProtocolTCP      Protocol = 6
+	ProtocolUDP      Protocol = 17
+	ProtocolRouting  Protocol = 43
+	ProtocolFragment Protocol = 44
+	ProtocolAuth     Protocol = 51
+	ProtocolICMPv6   Protocol = 58
+	ProtocolNoNext   Protocol = 59
+	ProtocolDestOpts Protocol = 60
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolHopByHop:
+		return "Hop-by-Hop"
+	case ProtocolICMP:
+		return "ICMP"
+	case ProtocolTCP:
+		return "TCP"
+	case ProtocolUDP:
+		return "UDP"
+	case ProtocolRouting:
+		return "Routing"
+	case ProtocolFragment:
+		return "Fragment"
+	case ProtocolAuth:
+		return "AH"
+	case ProtocolICMPv6:
+		return "ICMPv6"
+	case ProtocolNoNext:
+		return "No Next Header"
+	case ProtocolDestOpts:
+		return "Destination Options"
+	}
+	return fmt.Sprintf("%d", uint8(p))
+}
+
+// Checksum returns the internet checksum (RFC 1071) of b, which is 0 for data that includes a valid checksum
+func Checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// Option is a type-length-value option of an IPv4 or TCP header
+type Option struct {
+	Type uint8
+	Data []byte // Excluding the type & length bytes, aliases the packet's buffer
+}
+
+// readOptions reads options up to the end of p, where types 0 & 1 are the single byte end of list & no-op.
+// Error offsets are relative to base, the offset of p's data in the parent packet
+func readOptions(p *decoder.Packet, base int) ([]Option, error) {
+	var v []Option
+	for p.RemainingLength() > 0 {
+		offset := p.Index()
+		t := p.Byte()
+		if t == 0 {
+			break
+		}
+		if t == 1 {
+			continue
+		}
+		length := int(p.Byte())
+		if p.Err != nil || length < 2 || length-2 > p.RemainingLength() {
+			return nil, &decoder.OffsetError{Offset: base + offset, Err: ErrInvalidHeader}
+		}
+		v = append(v, Option{Type: t, Data: p.Bytes(length - 2)})
+	}
+	return v, nil
+}
+
+// fail restores the packet to the mark and returns the error at the given offset
+func fail(p *decoder.Packet, m decoder.Mark, offset int, err error) error {
+	p.Restore(m)
+	return &decoder.OffsetError{Offset: offset, Err: err}
+}
@@ -0,0 +1,47 @@
+package layers
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want uint16
+	}{
+		{"450000730000400040110000c0a80001c0a800c7", 0xb861},
+		{"45000073000040004011b861c0a80001c0a800c7", 0},
+		{"0001f203f4f5f6f7", 0x220d},
+		{"01", 0xfeff},
+		{"", 0xffff},
+	}
+	for _, test := range tests {
+		if v := Checksum(mustHex(test.hex)); v != test.want {
+			t.Errorf("%s: expected 0x%04x got 0x%04x", test.hex, test.want, v)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	if s := EtherTypeIPv6.String(); s != "IPv6" {
+		t.Errorf("expected IPv6 got %s", s)
+	}
+	if s := EtherType(0x88cc).String(); s != "0x88cc" {
+		t.Errorf("expected 0x88cc got %s", s)
+	}
+	if s := ProtocolUDP.String(); s != "UDP" {
+		t.Errorf("expected UDP got %s", s)
+	}
+	if s := Protocol(132).String(); s != "132" {
+		t.Errorf("expected 132 got %s", s)
+	}
+}
@@ -0,0 +1,135 @@
+package layers
+
+import (
+	"encoding/binary"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// UDP is a UDP header
+type UDP struct {
+	SrcPort  uint16
+	DstPort  uint16
+	Length   uint16 // The length of the header & payload
+	Checksum uint16
+}
+
+// DecodeUDP reads a UDP header and returns it with its payload. The packet is set to big endian,
+// errors are an *decoder.OffsetError and leave the packet unchanged
+func DecodeUDP(p *decoder.Packet) (UDP, *decoder.Packet, error) {
+	m, start := p.Mark(), p.Index()
+	p.SetBigEndian()
+	var h UDP
+	h.SrcPort = p.Uint16()
+	h.DstPort = p.Uint16()
+	h.Length = p.Uint16()
+	h.Checksum = p.Uint16()
+	if p.Err != nil {
+		return UDP{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+	if h.Length < 8 {
+		return UDP{}, nil, fail(p, m, start+4, ErrInvalidHeader)
+	}
+	payload := p.Sub(int(h.Length) - 8)
+	if payload == nil {
+		return UDP{}, nil, fail(p, m, start+4, decoder.ErrReadPastEndData)
+	}
+	return h, payload, nil
+}
+
+// TCPFlags are the control bits of a TCP header
+type TCPFlags uint16
+
+// TCP flags
+const (
+	TCPFlagFIN TCPFlags = 1 << iota
+	TCPFlagSYN
+	TCPFlagRST
+	TCPFlagPSH
+	TCPFlagACK
+	TCPFlagURG
+	TCPFlagECE
+	TCPFlagCWR
+	TCPFlagNS
+)
+
+// Has returns true if all the given flags are set
+func (f TCPFlags) Has(flags TCPFlags) bool {
+	return f&flags == flags
+}
+
+// TCP option kinds
+const (
+	TCPOptionMSS           = 2
+	TCPOptionWindowScale   = 3
+	TCPOptionSACKPermitted = 4
+	TCPOptionSACK          = 5
+	TCPOptionTimestamps    = 8
+)
+
+// TCP is a TCP header
+type TCP struct {
+	SrcPort    uint16
+	DstPort    uint16
+	Seq        uint32
+	Ack        uint32
+	DataOffset uint8 // The header length in 32 bit words
+	Flags      TCPFlags
+	Window     uint16
+	Checksum   uint16
+	Urgent     uint16
+	Options    []Option
+}
+
+// Option returns the first option of the given kind
+func (h TCP) Option(kind uint8) (Option, bool) {
+	for _, o := range h.Options {
+		if o.Type == kind {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// MSS returns the maximum segment size option
+func (h TCP) MSS() (uint16, bool) {
+	o, ok := h.Option(TCPOptionMSS)
+	if !ok || len(o.Data) != 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(o.Data), true
+}
+
+// DecodeTCP reads a TCP header with its options and returns it with the rest of the segment as the payload.
+// The packet is set to big endian, errors are an *decoder.OffsetError and leave the packet unchanged
+func DecodeTCP(p *decoder.Packet) (TCP, *decoder.Packet, error) {
+	m, start := p.Mark(), p.Index()
+	p.SetBigEndian()
+	var h TCP
+	h.SrcPort = p.Uint16()
+	h.DstPort = p.Uint16()
+	h.Seq = p.Uint32()
+	h.Ack = p.Uint32()
+	h.DataOffset = uint8(p.Bits(4))
+	p.Bits(3) // Reserved
+	h.Flags = TCPFlags(p.Bits(9))
+	h.Window = p.Uint16()
+	h.Checksum = p.Uint16()
+	h.Urgent = p.Uint16()
+	if p.Err != nil {
+		return TCP{}, nil, fail(p, m, start, decoder.ErrReadPastEndData)
+	}
+	if h.DataOffset < 5 {
+		return TCP{}, nil, fail(p, m, start+12, ErrInvalidHeader)
+	}
+	options := p.Sub(int(h.DataOffset)*4 - 20)
+	if options == nil {
+		return TCP{}, nil, fail(p, m, start+12, decoder.ErrReadPastEndData)
+	}
+	var err error
+	if h.Options, err = readOptions(options, start+20); err != nil {
+		p.Restore(m)
+		return TCP{}, nil, err
+	}
+	return h, p.Sub(p.RemainingLength()), nil
+}
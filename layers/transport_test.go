@@ -0,0 +1,101 @@
+package layers
+
+import (
+	"errors"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestDecodeUDP(t *testing.T) {
+	p := decoder.New(mustHex("3039" + "0035" + "000c" + "abcd" + "01020304" + "ffff"))
+	h, payload, err := DecodeUDP(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.SrcPort != 12345 || h.DstPort != 53 || h.Length != 12 || h.Checksum != 0xabcd {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if payload.RemainingLength() != 4 || p.RemainingLength() != 2 {
+		t.Errorf("unexpected payload %v", payload.PeekBytes())
+	}
+
+	tests := []struct {
+		hex string
+		err error
+	}{
+		{"30390035000c", decoder.ErrReadPastEndData},
+		{"303900350004abcd", ErrInvalidHeader},
+		{"30390035000cabcd0102", decoder.ErrReadPastEndData},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		if _, _, err := DecodeUDP(p); !errors.Is(err, test.err) || p.Index() != 0 {
+			t.Errorf("%s: expected %v got %v", test.hex, test.err, err)
+		}
+	}
+}
+
+func TestDecodeTCP(t *testing.T) {
+	// Data offset of 8 with MSS, NOP, window scale, SACK permitted & end of list options
+	p := decoder.New(mustHex("c000" + "0050" + "00000001" + "00000002" + "8" + "0" + "12" + "ffff" + "abcd" + "0000" +
+		"020405b4" + "01030307" + "04020000" + "474554"))
+	h, payload, err := DecodeTCP(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.SrcPort != 49152 || h.DstPort != 80 || h.Seq != 1 || h.Ack != 2 || h.DataOffset != 8 ||
+		h.Window != 0xffff || h.Checksum != 0xabcd {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if h.Flags != TCPFlagSYN|TCPFlagACK || !h.Flags.Has(TCPFlagSYN) || h.Flags.Has(TCPFlagSYN|TCPFlagFIN) {
+		t.Errorf("unexpected flags %09b", h.Flags)
+	}
+	if len(h.Options) != 3 {
+		t.Fatalf("expected 3 options got %+v", h.Options)
+	}
+	if mss, ok := h.MSS(); !ok || mss != 1460 {
+		t.Errorf("expected MSS 1460 got %d", mss)
+	}
+	if o, ok := h.Option(TCPOptionWindowScale); !ok || len(o.Data) != 1 || o.Data[0] != 7 {
+		t.Errorf("unexpected window scale %+v", o)
+	}
+	if _, ok := h.Option(TCPOptionSACKPermitted); !ok {
+		t.Error("expected SACK permitted")
+	}
+	if s := string(payload.PeekRemainingBytes()); s != "GET" {
+		t.Errorf("expected GET got %q", s)
+	}
+
+	// NS & CWR flags
+	h, _, _ = DecodeTCP(decoder.New(mustHex("c0000050" + "00000001" + "00000002" + "5180" + "000000000000")))
+	if !h.Flags.Has(TCPFlagNS | TCPFlagCWR) {
+		t.Errorf("unexpected flags %09b", h.Flags)
+	}
+}
+
+func TestDecodeTCPErrors(t *testing.T) {
+	header := "c000" + "0050" + "00000001" + "00000002"
+	tests := []struct {
+		name   string
+		hex    string
+		err    error
+		offset int
+	}{
+		{"short", header, decoder.ErrReadPastEndData, 0},
+		{"data offset", header + "4002" + "ffff00000000", ErrInvalidHeader, 12},
+		{"options", header + "6002" + "ffff00000000" + "0204", decoder.ErrReadPastEndData, 12},
+		{"option length", header + "6002" + "ffff00000000" + "02080000", ErrInvalidHeader, 20},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		_, _, err := DecodeTCP(p)
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("%s: expected %v at %d got %v", test.name, test.err, test.offset, err)
+		}
+		if p.Index() != 0 || p.Err != nil {
+			t.Errorf("%s: expected packet restored got index %d err %v", test.name, p.Index(), p.Err)
+		}
+	}
+}
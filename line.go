@@ -0,0 +1,147 @@
+package decoder
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+var ErrLineTooLong = errors.New("line too long")
+
+// LineConfig controls how lines are split
+type LineConfig struct {
+	Terminators    []byte // Bytes that end a line, when both CR & LF are included CRLF ends a single line
+	KeepTerminator bool   // Include the terminator in the returned line
+	MaxLength      int    // The longest line allowed (excluding the terminator), 0 for no limit
+}
+
+// DefaultLineConfig splits lines on CR, LF or CRLF
+var DefaultLineConfig = LineConfig{
+	Terminators: []byte{CR, LF},
+}
+
+// SetLineConfig sets how Line splits lines
+func (p *Packet) SetLineConfig(c LineConfig) {
+	p.lineConfig = &c
+}
+
+// Line returns the line of text at the internal pointer and increments it past the terminator.
+// A final line without a terminator is returned as is, at the end of the data Err is set to ErrReadNoData.
+// If the line is longer than the max length Err is set to an *OffsetError wrapping ErrLineTooLong and
+// the line is skipped, so the next line can still be read once Err is cleared
+func (p *Packet) Line() string {
+	c := &DefaultLineConfig
+	if p.lineConfig != nil {
+		c = p.lineConfig
+	}
+	if p.idx >= p.length {
+		p.Err = ErrReadNoData
+		return ""
+	}
+	data := p.buf[p.idx:p.length]
+	end, next := c.split(data)
+	if c.MaxLength > 0 && end > c.MaxLength {
+		p.Err = &OffsetError{Offset: p.idx + c.MaxLength, Err: ErrLineTooLong}
+		p.idx += next
+		return ""
+	}
+	if c.KeepTerminator {
+		end = next
+	}
+	v := p.text(data[:end])
+	p.idx += next
+	return v
+}
+
+// split returns the length of the line and where the next line starts
+func (c *LineConfig) split(data []byte) (end int, next int) {
+	for i, b := range data {
+		if bytes.IndexByte(c.Terminators, b) == -1 {
+			continue
+		}
+		if b == CR && i+1 < len(data) && data[i+1] == LF && bytes.IndexByte(c.Terminators, LF) != -1 {
+			return i, i + 2
+		}
+		return i, i + 1
+	}
+	return len(data), len(data)
+}
+
+// LineReader reads lines from a stream using the same rules as Packet.Line
+type LineReader struct {
+	r        *bufio.Reader
+	config   LineConfig
+	encoding TextEncoding
+	buf      []byte
+	cr       bool // The last line ended with a CR, so a following LF completes its CRLF
+}
+
+// NewLineReader returns a LineReader reading from r
+func NewLineReader(r io.Reader, c LineConfig) *LineReader {
+	return &LineReader{
+		r:      bufio.NewReader(r),
+		config: c,
+	}
+}
+
+// SetEncoding sets the text encoding used to convert lines to strings
+func (lr *LineReader) SetEncoding(e TextEncoding) {
+	lr.encoding = e
+}
+
+// Line returns the next line. A final line without a terminator is returned with a nil error,
+// after which io.EOF is returned. If a line exceeds the max length ErrLineTooLong is returned
+// and the rest of that line is discarded. A line ending in CR is returned without waiting for
+// more data, if the LF of a CRLF has not yet arrived it is dropped by the next call
+func (lr *LineReader) Line() (string, error) {
+	lr.buf = lr.buf[:0]
+	tooLong := false
+	for {
+		b, err := lr.r.ReadByte()
+		if lr.cr {
+			lr.cr = false
+			if err == nil && b == LF {
+				continue
+			}
+		}
+		if err == io.EOF {
+			if tooLong {
+				return "", ErrLineTooLong
+			}
+			if len(lr.buf) == 0 {
+				return "", io.EOF
+			}
+			return decodeText(lr.encoding, lr.buf), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if bytes.IndexByte(lr.config.Terminators, b) == -1 {
+			if lr.config.MaxLength > 0 && len(lr.buf) >= lr.config.MaxLength {
+				tooLong = true
+				continue
+			}
+			lr.buf = append(lr.buf, b)
+			continue
+		}
+		line := len(lr.buf)
+		lr.buf = append(lr.buf, b)
+		if b == CR && bytes.IndexByte(lr.config.Terminators, LF) != -1 {
+			// Only look ahead at data already buffered, as reading more could block
+			if lr.r.Buffered() == 0 {
+				lr.cr = true
+			} else if next, _ := lr.r.Peek(1); next[0] == LF {
+				lr.r.ReadByte()
+				lr.buf = append(lr.buf, LF)
+			}
+		}
+		if tooLong {
+			return "", ErrLineTooLong
+		}
+		if !lr.config.KeepTerminator {
+			lr.buf = lr.buf[:line]
+		}
+		return decodeText(lr.encoding, lr.buf), nil
+	}
+}
@@ -0,0 +1,132 @@
+package decoder
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const lineData = "AT+CSQ\r\n+CSQ: 21,99\rOK\n\nlast"
+
+func TestLine(t *testing.T) {
+	p := New([]byte(lineData))
+	for _, expect := range []string{"AT+CSQ", "+CSQ: 21,99", "OK", "", "last"} {
+		if s := p.Line(); s != expect {
+			t.Errorf("expected '%s' got '%s'", expect, s)
+		}
+		if p.Err != nil {
+			t.Errorf("got unexpected err: %s", p.Err)
+		}
+	}
+	p.Line()
+	if p.Err != ErrReadNoData {
+		t.Errorf("expected ErrReadNoData got %v", p.Err)
+	}
+}
+
+func TestLineConfig(t *testing.T) {
+	p := New([]byte(lineData))
+	p.SetLineConfig(LineConfig{
+		Terminators:    []byte{LF},
+		KeepTerminator: true,
+	})
+	for _, expect := range []string{"AT+CSQ\r\n", "+CSQ: 21,99\rOK\n", "\n", "last"} {
+		if s := p.Line(); s != expect {
+			t.Errorf("expected %q got %q", expect, s)
+		}
+	}
+
+	p = New([]byte(lineData))
+	p.SetLineConfig(LineConfig{
+		Terminators: []byte{CR, LF},
+		MaxLength:   6,
+	})
+	if s := p.Line(); s != "AT+CSQ" {
+		t.Errorf("expected 'AT+CSQ' got '%s'", s)
+	}
+	p.Line()
+	if e, ok := p.Err.(*OffsetError); !ok || e.Err != ErrLineTooLong || e.Offset != 14 {
+		t.Errorf("expected ErrLineTooLong at 14 got %v", p.Err)
+	}
+	if p.Index() != 20 {
+		t.Errorf("expected the long line to be skipped to 20 got %d", p.Index())
+	}
+
+	p = New([]byte("toolongline\nok\n"))
+	p.SetLineConfig(LineConfig{
+		Terminators: []byte{LF},
+		MaxLength:   4,
+	})
+	if s := p.Line(); s != "" || !errors.Is(p.Err, ErrLineTooLong) {
+		t.Errorf("expected ErrLineTooLong got %q, %v", s, p.Err)
+	}
+	p.Err = nil
+	if s := p.Line(); s != "ok" || p.Err != nil {
+		t.Errorf("expected 'ok' got %q, %v", s, p.Err)
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	lr := NewLineReader(strings.NewReader(lineData), DefaultLineConfig)
+	for _, expect := range []string{"AT+CSQ", "+CSQ: 21,99", "OK", "", "last"} {
+		s, err := lr.Line()
+		if err != nil {
+			t.Errorf("got unexpected err: %s", err)
+		}
+		if s != expect {
+			t.Errorf("expected '%s' got '%s'", expect, s)
+		}
+	}
+	if _, err := lr.Line(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+
+	lr = NewLineReader(strings.NewReader("short\r\ntoo long line\r\nok\r\n"), LineConfig{
+		Terminators:    []byte{CR, LF},
+		KeepTerminator: true,
+		MaxLength:      5,
+	})
+	for _, test := range []struct {
+		expect string
+		err    error
+	}{
+		{"short\r\n", nil},
+		{"", ErrLineTooLong},
+		{"ok\r\n", nil},
+		{"", io.EOF},
+	} {
+		s, err := lr.Line()
+		if s != test.expect || err != test.err {
+			t.Errorf("expected %q, %v got %q, %v", test.expect, test.err, s, err)
+		}
+	}
+}
+
+func TestLineReaderCRWithoutBlocking(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("abc\r"))
+
+	lr := NewLineReader(r, DefaultLineConfig)
+	done := make(chan string)
+	go func() {
+		s, _ := lr.Line()
+		done <- s
+	}()
+	select {
+	case s := <-done:
+		if s != "abc" {
+			t.Errorf("expected 'abc' got %q", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("line ending in CR was held back waiting for more data")
+	}
+
+	// The LF of the CRLF arrives later and is dropped
+	go w.Write([]byte("\ndef\r\n"))
+	if s, err := lr.Line(); s != "def" || err != nil {
+		t.Errorf("expected 'def' got %q, %v", s, err)
+	}
+}
@@ -0,0 +1,43 @@
+package decoder
+
+import "encoding/binary"
+
+// Mark is a saved read state returned by Packet.Mark
+type Mark struct {
+	idx    int
+	bit    int
+	err    error
+	endian binary.ByteOrder
+}
+
+// Mark returns the current read & bit position, error and endian so they can be put back with Restore
+func (p *Packet) Mark() Mark {
+	return Mark{
+		idx:    p.idx,
+		bit:    p.bit,
+		err:    p.Err,
+		endian: p.endian,
+	}
+}
+
+// Restore puts back the read & bit position, error and endian saved by Mark
+func (p *Packet) Restore(m Mark) {
+	p.idx = m.idx
+	p.bit = m.bit
+	p.Err = m.err
+	p.endian = m.endian
+}
+
+// Try runs f, and if it returns an error or sets Err the packet is restored to how it was before f ran.
+// The error is returned, allowing alternative parses to be tried in turn
+func (p *Packet) Try(f func(*Packet) error) error {
+	m := p.Mark()
+	err := f(p)
+	if err == nil && p.Err != m.err {
+		err = p.Err
+	}
+	if err != nil {
+		p.Restore(m)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarkRestore(t *testing.T) {
+	p := New(data1)
+	p.Byte()
+	m := p.Mark()
+
+	p.SetLittleEndian()
+	p.Uint32()
+	p.Uint32()
+	if p.Err == nil {
+		t.Fatal("expected error reading past end of data")
+	}
+
+	p.Restore(m)
+	if p.Index() != 1 || p.Err != nil {
+		t.Errorf("expected index 1 & no error got %d & %v", p.Index(), p.Err)
+	}
+	if v := p.Uint16(); v != 0x0102 {
+		t.Errorf("expected big endian 0x0102 got %X", v)
+	}
+}
+
+func TestTry(t *testing.T) {
+	p := New([]byte("OK 12"))
+	errNotError := errors.New("not an error response")
+
+	err := p.Try(func(p *Packet) error {
+		if !p.ExpectString("ERROR") {
+			return p.Err
+		}
+		return nil
+	})
+	if err == nil {
+		t.Error("expected error")
+	}
+	if p.Index() != 0 || p.Err != nil {
+		t.Errorf("expected index 0 & no error got %d & %v", p.Index(), p.Err)
+	}
+
+	err = p.Try(func(p *Packet) error {
+		p.Bytes(3)
+		return errNotError
+	})
+	if err != errNotError || p.Index() != 0 {
+		t.Errorf("expected errNotError at 0 got %v at %d", err, p.Index())
+	}
+
+	// Setting Err without returning it still rolls back
+	err = p.Try(func(p *Packet) error {
+		p.Bytes(3)
+		p.Uint64()
+		return nil
+	})
+	if err != ErrReadPastEndData || p.Index() != 0 || p.Err != nil {
+		t.Errorf("expected ErrReadPastEndData at 0 got %v at %d", err, p.Index())
+	}
+
+	var n int
+	err = p.Try(func(p *Packet) error {
+		p.ExpectString("OK ")
+		n = p.AsciiInt()
+		return nil
+	})
+	if err != nil || n != 12 || !p.EOF() {
+		t.Errorf("expected 12 at EOF got %d err %v", n, err)
+	}
+}
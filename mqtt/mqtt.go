@@ -0,0 +1,325 @@
+// Package mqtt decodes MQTT 3.1.1 & 5.0 control packets using a *decoder.Packet.
+package mqtt
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// Protocol versions, as given by the protocol level of a CONNECT packet
+const (
+	Version31  = 3
+	Version311 = 4
+	Version5   = 5
+)
+
+// PacketType is the type of a control packet
+type PacketType uint8
+
+// Control packet types
+const (
+	CONNECT     PacketType = 1
+	CONNACK     PacketType = 2
+	PUBLISH     PacketType = 3
+	PUBACK      PacketType = 4
+	PUBREC      PacketType = 5
+	PUBREL      PacketType = 6
+	PUBCOMP     PacketType = 7
+	SUBSCRIBE   PacketType = 8
+	SUBACK      PacketType = 9
+	UNSUBSCRIBE PacketType = 10
+	UNSUBACK    PacketType = 11
+	PINGREQ     PacketType = 12
+	PINGRESP    PacketType = 13
+	DISCONNECT  PacketType = 14
+	AUTH        PacketType = 15
+)
+
+var packetTypeNames = [...]string{"", "CONNECT", "CONNACK", "PUBLISH", "PUBACK", "PUBREC", "PUBREL", "PUBCOMP",
+	"SUBSCRIBE", "SUBACK", "UNSUBSCRIBE", "UNSUBACK", "PINGREQ", "PINGRESP", "DISCONNECT", "AUTH"}
+
+func (t PacketType) String() string {
+	if t > 0 && int(t) < len(packetTypeNames) {
+		return packetTypeNames[t]
+	}
+	return fmt.Sprintf("reserved (%d)", uint8(t))
+}
+
+// ReasonCode is an MQTT 5 reason code. Codes of 0x80 and above are failures and are used as errors,
+// decoding errors being MalformedPacket, ProtocolError or UnsupportedProtocolVersion
+type ReasonCode uint8
+
+// Reason codes
+const (
+	Success                     ReasonCode = 0x00
+	GrantedQoS1                 ReasonCode = 0x01
+	GrantedQoS2                 ReasonCode = 0x02
+	DisconnectWithWill          ReasonCode = 0x04
+	UnspecifiedError            ReasonCode = 0x80
+	MalformedPacket             ReasonCode = 0x81
+	ProtocolError               ReasonCode = 0x82
+	ImplementationSpecificError ReasonCode = 0x83
+	UnsupportedProtocolVersion  ReasonCode = 0x84
+	ClientIdentifierNotValid    ReasonCode = 0x85
+	BadUsernameOrPassword       ReasonCode = 0x86
+	NotAuthorized               ReasonCode = 0x87
+	ServerUnavailable           ReasonCode = 0x88
+	ServerBusy                  ReasonCode = 0x89
+	Banned                      ReasonCode = 0x8a
+	ServerShuttingDown          ReasonCode = 0x8b
+	KeepAliveTimeout            ReasonCode = 0x8d
+	SessionTakenOver            ReasonCode = 0x8e
+	TopicFilterInvalid          ReasonCode = 0x8f
+	TopicNameInvalid            ReasonCode = 0x90
+	PacketTooLarge              ReasonCode = 0x95
+	QuotaExceeded               ReasonCode = 0x97
+	PayloadFormatInvalid        ReasonCode = 0x99
+	QoSNotSupported             ReasonCode = 0x9b
+)
+
+var reasonCodeNames = map[ReasonCode]string{
+	Success:                     "success",
+	GrantedQoS1:                 "granted QoS 1",
+	GrantedQoS2:                 "granted QoS 2",
+	DisconnectWithWill:          "disconnect with will message",
+	UnspecifiedError:            "unspecified error",
+	MalformedPacket:             "malformed packet",
+	ProtocolError:               "protocol error",
+	ImplementationSpecificError: "implementation specific error",
+	UnsupportedProtocolVersion:  "unsupported protocol version",
+	ClientIdentifierNotValid:    "client identifier not valid",
+	BadUsernameOrPassword:       "bad user name or password",
+	NotAuthorized:               "not authorized",
+	ServerUnavailable:           "server unavailable",
+	ServerBusy:                  "server busy",
+	Banned:                      "banned",
+	ServerShuttingDown:          "server shutting down",
+	KeepAliveTimeout:            "keep alive timeout",
+	SessionTakenOver:            "session taken over",
+	TopicFilterInvalid:          "topic filter invalid",
+	TopicNameInvalid:            "topic name invalid",
+	PacketTooLarge:              "packet too large",
+	QuotaExceeded:               "quota exceeded",
+	PayloadFormatInvalid:        "payload format invalid",
+	QoSNotSupported:             "QoS not supported",
+}
+
+func (c ReasonCode) String() string {
+	if s, ok := reasonCodeNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("reason code 0x%02x", uint8(c))
+}
+
+func (c ReasonCode) Error() string {
+	return c.String()
+}
+
+// IsError returns true for failure reason codes
+func (c ReasonCode) IsError() bool {
+	return c >= 0x80
+}
+
+// FixedHeader is the header common to all control packets
+type FixedHeader struct {
+	PacketType      PacketType
+	Flags           uint8 // The low 4 bits of the first byte
+	RemainingLength int
+	Offset          int // The offset of the packet in the data
+}
+
+// Type returns the packet's type
+func (h FixedHeader) Type() PacketType {
+	return h.PacketType
+}
+
+// ControlPacket is one of the decoded packet structs
+type ControlPacket interface {
+	Type() PacketType
+}
+
+// Reader reads consecutive control packets
+type Reader struct {
+	p         *decoder.Packet
+	Version   uint8 // The protocol version, set by reading a CONNECT packet and defaulting to 3.1.1
+	MaxLength int   // The largest remaining length allowed, 0 for the protocol's limit of 268,435,455
+}
+
+// NewReader returns a Reader of the control packets from the packet's current position, the packet's
+// byte order is left as it is
+func NewReader(p *decoder.Packet) *Reader {
+	return &Reader{p: p, Version: Version311}
+}
+
+// Next returns the next control packet, or io.EOF at the end of the data. Packets are returned as pointers to
+// the structs of their type, with types that are not decoded returned as *Raw. An incomplete packet returns
+// decoder.ErrReadPastEndData, others errors are a ReasonCode. Errors are an *decoder.OffsetError and
+// leave the packet unchanged
+func (r *Reader) Next() (ControlPacket, error) {
+	p := r.p
+	if p.RemainingLength() == 0 {
+		return nil, io.EOF
+	}
+	m := p.Mark()
+	h := FixedHeader{Offset: p.Index()}
+	b := p.Byte()
+	h.PacketType = PacketType(b >> 4)
+	h.Flags = b & 0x0f
+	length, err := readVBI(p)
+	if err != nil {
+		p.Restore(m)
+		return nil, err
+	}
+	h.RemainingLength = length
+	if r.MaxLength > 0 && length > r.MaxLength {
+		p.Restore(m)
+		return nil, offsetError(h.Offset+1, PacketTooLarge)
+	}
+	body := p.Sub(length)
+	if body == nil {
+		p.Restore(m)
+		return nil, &decoder.OffsetError{Offset: h.Offset, Err: decoder.ErrReadPastEndData}
+	}
+	body.SetBigEndian()
+
+	d := &packetDecoder{p: body, base: p.Index() - length, version: r.Version}
+	cp, err := d.decode(h)
+	if err != nil {
+		p.Restore(m)
+		return nil, err
+	}
+	if c, ok := cp.(*Connect); ok {
+		r.Version = c.ProtocolVersion
+	}
+	return cp, nil
+}
+
+// All returns all the remaining control packets
+func (r *Reader) All() ([]ControlPacket, error) {
+	var v []ControlPacket
+	for {
+		cp, err := r.Next()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return v, err
+		}
+		v = append(v, cp)
+	}
+}
+
+// readVBI reads a variable byte integer of up to 4 bytes
+func readVBI(p *decoder.Packet) (int, error) {
+	offset := p.Index()
+	v := 0
+	for i := 0; i < 4; i++ {
+		b := p.Byte()
+		if p.Err != nil {
+			p.Err = nil
+			return 0, &decoder.OffsetError{Offset: offset, Err: decoder.ErrReadPastEndData}
+		}
+		v |= int(b&0x7f) << (7 * uint(i))
+		if b&0x80 == 0 {
+			if i > 0 && b == 0 {
+				break // Not encoded in the fewest bytes
+			}
+			return v, nil
+		}
+	}
+	return 0, offsetError(offset, MalformedPacket)
+}
+
+// packetDecoder reads the variable header & payload of a packet, reporting errors as ReasonCodes at
+// offsets in the parent packet
+type packetDecoder struct {
+	p       *decoder.Packet
+	base    int // The offset of the body in the parent packet
+	version uint8
+	err     error
+}
+
+// fail records the first error at the current position, reads after which return zero values
+func (d *packetDecoder) fail(code ReasonCode) {
+	if d.err == nil {
+		d.err = offsetError(d.base+d.p.Index(), code)
+	}
+}
+
+func (d *packetDecoder) byte() byte {
+	v := d.p.Byte()
+	if d.p.Err != nil {
+		d.fail(MalformedPacket)
+	}
+	return v
+}
+
+func (d *packetDecoder) uint16() uint16 {
+	v := d.p.Uint16()
+	if d.p.Err != nil {
+		d.fail(MalformedPacket)
+	}
+	return v
+}
+
+func (d *packetDecoder) uint32() uint32 {
+	v := d.p.Uint32()
+	if d.p.Err != nil {
+		d.fail(MalformedPacket)
+	}
+	return v
+}
+
+func (d *packetDecoder) vbi() int {
+	offset := d.p.Index()
+	v, err := readVBI(d.p)
+	if err != nil && d.err == nil {
+		d.err = offsetError(d.base+offset, MalformedPacket)
+	}
+	return v
+}
+
+// binary reads u16 length prefixed binary data
+func (d *packetDecoder) binary() []byte {
+	v := d.p.PrefixedBytes(decoder.LenUint16)
+	if d.p.Err != nil {
+		d.fail(MalformedPacket)
+	}
+	return v
+}
+
+// string reads a u16 length prefixed UTF-8 string, which must not contain U+0000
+func (d *packetDecoder) string() string {
+	offset := d.p.Index()
+	b := d.binary()
+	if d.err == nil && !validString(b) {
+		d.err = offsetError(d.base+offset, MalformedPacket)
+	}
+	return string(b)
+}
+
+func offsetError(offset int, code ReasonCode) error {
+	return &decoder.OffsetError{Offset: offset, Err: code}
+}
+
+func validString(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, c := range b {
+		if c == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// end checks the whole body has been read
+func (d *packetDecoder) end() {
+	if d.p.RemainingLength() != 0 {
+		d.fail(MalformedPacket)
+	}
+}
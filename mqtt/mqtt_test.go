@@ -0,0 +1,140 @@
+package mqtt
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestReadVBI(t *testing.T) {
+	tests := []struct {
+		hex string
+		v   int
+		err error
+	}{
+		{"00", 0, nil},
+		{"7f", 127, nil},
+		{"8001", 128, nil},
+		{"ff7f", 16383, nil},
+		{"808001", 16384, nil},
+		{"ffffff7f", 268435455, nil},
+		{"ffffffff01", 0, MalformedPacket},
+		{"8000", 0, MalformedPacket},
+		{"80", 0, decoder.ErrReadPastEndData},
+	}
+	for _, test := range tests {
+		v, err := readVBI(decoder.New(mustHex(test.hex)))
+		if v != test.v || !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("%s: expected %d, %v got %d, %v", test.hex, test.v, test.err, v, err)
+		}
+	}
+}
+
+func TestReaderVersion(t *testing.T) {
+	// An MQTT 5 CONNECT switches the reader to MQTT 5, so the DISCONNECT's reason code is read
+	b := mustHex("1010" + "00044d515454" + "05" + "02" + "003c" + "00" + "0003616263" +
+		"c000" +
+		"e0020400")
+	r := NewReader(decoder.New(b))
+	if r.Version != Version311 {
+		t.Errorf("expected default version 4 got %d", r.Version)
+	}
+	packets, err := r.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 3 || r.Version != Version5 {
+		t.Fatalf("expected 3 packets & version 5 got %d & %d", len(packets), r.Version)
+	}
+	if _, ok := packets[1].(*Pingreq); !ok || packets[1].Type() != PINGREQ {
+		t.Errorf("expected PINGREQ got %T", packets[1])
+	}
+	d, ok := packets[2].(*Disconnect)
+	if !ok || d.ReasonCode != DisconnectWithWill || d.Err() != nil || d.Offset != 20 {
+		t.Errorf("unexpected packet %+v", packets[2])
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
+
+func TestReaderKeepsByteOrder(t *testing.T) {
+	p := decoder.New([]byte{0x32, 0x06, 0x00, 0x01, 'a', 0x00, 0x05, 'x', 0x01, 0x02})
+	p.SetLittleEndian()
+	cp, err := NewReader(p).Next()
+	if pub, ok := cp.(*Publish); err != nil || !ok || pub.PacketID != 5 || pub.Topic != "a" {
+		t.Errorf("unexpected packet %+v err %v", cp, err)
+	}
+	if v := p.Uint16(); v != 0x0201 {
+		t.Errorf("expected the packet to still be little endian got 0x%04X", v)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		hex       string
+		version   uint8
+		maxLength int
+		err       error
+		offset    int
+	}{
+		{"incomplete length", "e0", 5, 0, decoder.ErrReadPastEndData, 1},
+		{"long length", "30ffffffff7f", 5, 0, MalformedPacket, 1},
+		{"non-minimal length", "308000", 5, 0, MalformedPacket, 1},
+		{"incomplete body", "10050004", 4, 0, decoder.ErrReadPastEndData, 0},
+		{"too large", "3003000161", 5, 2, PacketTooLarge, 1},
+		{"reserved type", "0000", 5, 0, MalformedPacket, 0},
+		{"reserved flags", "c100", 5, 0, MalformedPacket, 0},
+		{"trailing data", "c00100", 5, 0, MalformedPacket, 2},
+		{"QoS 3", "3603000161", 5, 0, MalformedPacket, 0},
+		{"NUL in topic", "3004000261" + "00", 4, 0, MalformedPacket, 2},
+		{"invalid UTF-8", "3004000261" + "ff", 4, 0, MalformedPacket, 2},
+		{"zero packet id", "3205000161" + "0000", 4, 0, ProtocolError, 5},
+		{"protocol version", "100c00044d515454" + "06" + "02003c" + "0000", 4, 0, UnsupportedProtocolVersion, 8},
+		{"connect reserved flag", "100c00044d515454" + "04" + "03003c" + "0000", 4, 0, MalformedPacket, 9},
+		{"password without username", "100c00044d515454" + "04" + "42003c" + "0000", 4, 0, MalformedPacket, 9},
+		{"no subscriptions", "82030001" + "00", 5, 0, ProtocolError, 5},
+		{"subscription options", "82070001" + "00" + "000161" + "c0", 5, 0, MalformedPacket, 8},
+		{"3.1.1 subscription options", "82060001" + "000161" + "04", 4, 0, MalformedPacket, 7},
+		{"connack return code", "20020006", 4, 0, MalformedPacket, 3},
+		{"connack flags", "20020200", 4, 0, MalformedPacket, 2},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		r := NewReader(p)
+		r.Version = test.version
+		r.MaxLength = test.maxLength
+		_, err := r.Next()
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("%s: expected %v at %d got %v", test.name, test.err, test.offset, err)
+		}
+		if p.Index() != 0 || p.Err != nil {
+			t.Errorf("%s: expected packet restored got index %d err %v", test.name, p.Index(), p.Err)
+		}
+	}
+}
+
+func TestReasonCode(t *testing.T) {
+	if MalformedPacket.Error() != "malformed packet" || !MalformedPacket.IsError() || Success.IsError() {
+		t.Error("unexpected reason code")
+	}
+	if s := ReasonCode(0xfe).String(); s != "reason code 0xfe" {
+		t.Errorf("unexpected string %s", s)
+	}
+	if s := PacketType(0).String(); s != "reserved (0)" {
+		t.Errorf("unexpected string %s", s)
+	}
+}
@@ -0,0 +1,309 @@
+package mqtt
+
+// Connect is a CONNECT packet
+type Connect struct {
+	FixedHeader
+	ProtocolName    string
+	ProtocolVersion uint8
+	CleanStart      bool // Clean session in MQTT 3.1.1
+	KeepAlive       uint16
+	Properties      Properties
+	ClientID        string
+	Will            *Will // Set if the will flag is set
+	HasUsername     bool
+	Username        string
+	HasPassword     bool
+	Password        []byte
+}
+
+// Will is the will message of a CONNECT packet
+type Will struct {
+	QoS        uint8
+	Retain     bool
+	Properties Properties
+	Topic      string
+	Payload    []byte
+}
+
+// Connack is a CONNACK packet. In MQTT 3.1.1 the return code is converted to its MQTT 5 reason code
+type Connack struct {
+	FixedHeader
+	SessionPresent bool
+	ReasonCode     ReasonCode
+	Properties     Properties
+}
+
+// Err returns the reason code if the connection was refused
+func (c *Connack) Err() error {
+	if c.ReasonCode.IsError() {
+		return c.ReasonCode
+	}
+	return nil
+}
+
+// Publish is a PUBLISH packet
+type Publish struct {
+	FixedHeader
+	Dup        bool
+	QoS        uint8
+	Retain     bool
+	Topic      string
+	PacketID   uint16 // Only present for QoS 1 & 2
+	Properties Properties
+	Payload    []byte // Aliases the packet's buffer
+}
+
+// Subscribe is a SUBSCRIBE packet
+type Subscribe struct {
+	FixedHeader
+	PacketID      uint16
+	Properties    Properties
+	Subscriptions []Subscription
+}
+
+// Subscription is a topic filter & its options from a SUBSCRIBE packet
+type Subscription struct {
+	TopicFilter       string
+	QoS               uint8
+	NoLocal           bool // MQTT 5 only
+	RetainAsPublished bool // MQTT 5 only
+	RetainHandling    uint8
+}
+
+// Suback is a SUBACK packet. In MQTT 3.1.1 the return codes are held as reason codes, 0x80 being failure
+type Suback struct {
+	FixedHeader
+	PacketID    uint16
+	Properties  Properties
+	ReasonCodes []ReasonCode
+}
+
+// Pingreq is a PINGREQ packet
+type Pingreq struct {
+	FixedHeader
+}
+
+// Pingresp is a PINGRESP packet
+type Pingresp struct {
+	FixedHeader
+}
+
+// Disconnect is a DISCONNECT packet, MQTT 5 adding a reason code & properties
+type Disconnect struct {
+	FixedHeader
+	ReasonCode ReasonCode
+	Properties Properties
+}
+
+// Err returns the reason code if the disconnection is due to an error
+func (c *Disconnect) Err() error {
+	if c.ReasonCode.IsError() {
+		return c.ReasonCode
+	}
+	return nil
+}
+
+// Raw is a packet of a type that is not decoded
+type Raw struct {
+	FixedHeader
+	Body []byte // The variable header & payload, aliasing the packet's buffer
+}
+
+// reservedFlags are the required fixed header flags of each packet type other than PUBLISH
+var reservedFlags = [...]uint8{PUBREL: 0x2, SUBSCRIBE: 0x2, UNSUBSCRIBE: 0x2, AUTH: 0}
+
+func (d *packetDecoder) decode(h FixedHeader) (ControlPacket, error) {
+	if h.PacketType == 0 {
+		d.err = offsetError(h.Offset, MalformedPacket)
+		return nil, d.err
+	}
+	if h.PacketType != PUBLISH && h.Flags != reservedFlags[h.PacketType] {
+		d.err = offsetError(h.Offset, MalformedPacket)
+		return nil, d.err
+	}
+
+	var cp ControlPacket
+	switch h.PacketType {
+	case CONNECT:
+		cp = d.connect(h)
+	case CONNACK:
+		cp = d.connack(h)
+	case PUBLISH:
+		cp = d.publish(h)
+	case SUBSCRIBE:
+		cp = d.subscribe(h)
+	case SUBACK:
+		cp = d.suback(h)
+	case PINGREQ:
+		d.end()
+		cp = &Pingreq{FixedHeader: h}
+	case PINGRESP:
+		d.end()
+		cp = &Pingresp{FixedHeader: h}
+	case DISCONNECT:
+		cp = d.disconnect(h)
+	default:
+		cp = &Raw{FixedHeader: h, Body: d.p.PeekBytes()}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return cp, nil
+}
+
+func (d *packetDecoder) connect(h FixedHeader) *Connect {
+	c := &Connect{FixedHeader: h}
+	c.ProtocolName = d.string()
+	levelOffset := d.p.Index()
+	c.ProtocolVersion = d.byte()
+	if d.err != nil {
+		return nil
+	}
+	switch {
+	case c.ProtocolName == "MQTT" && (c.ProtocolVersion == Version311 || c.ProtocolVersion == Version5):
+	case c.ProtocolName == "MQIsdp" && c.ProtocolVersion == Version31:
+	default:
+		d.err = offsetError(d.base+levelOffset, UnsupportedProtocolVersion)
+		return nil
+	}
+	d.version = c.ProtocolVersion
+
+	flagsOffset := d.p.Index()
+	flags := d.byte()
+	c.CleanStart = flags&0x02 != 0
+	c.HasUsername = flags&0x80 != 0
+	c.HasPassword = flags&0x40 != 0
+	willQoS := flags >> 3 & 0x3
+	willRetain := flags&0x20 != 0
+	if flags&0x01 != 0 || willQoS == 3 || (flags&0x04 == 0 && (willQoS != 0 || willRetain)) ||
+		(d.version < Version5 && c.HasPassword && !c.HasUsername) {
+		d.err = offsetError(d.base+flagsOffset, MalformedPacket)
+		return nil
+	}
+	c.KeepAlive = d.uint16()
+	c.Properties = d.properties()
+
+	c.ClientID = d.string()
+	if flags&0x04 != 0 {
+		c.Will = &Will{QoS: willQoS, Retain: willRetain}
+		c.Will.Properties = d.properties()
+		c.Will.Topic = d.string()
+		c.Will.Payload = d.binary()
+	}
+	if c.HasUsername {
+		c.Username = d.string()
+	}
+	if c.HasPassword {
+		c.Password = d.binary()
+	}
+	d.end()
+	return c
+}
+
+// connackReturnCodes are the MQTT 5 reason codes of the MQTT 3.1.1 CONNACK return codes
+var connackReturnCodes = [...]ReasonCode{Success, UnsupportedProtocolVersion, ClientIdentifierNotValid,
+	ServerUnavailable, BadUsernameOrPassword, NotAuthorized}
+
+func (d *packetDecoder) connack(h FixedHeader) *Connack {
+	c := &Connack{FixedHeader: h}
+	flags := d.byte()
+	if flags&0xfe != 0 && d.err == nil {
+		d.err = offsetError(d.base, MalformedPacket)
+	}
+	c.SessionPresent = flags&0x01 != 0
+	c.ReasonCode = ReasonCode(d.byte())
+	if d.version < Version5 {
+		if int(c.ReasonCode) < len(connackReturnCodes) {
+			c.ReasonCode = connackReturnCodes[c.ReasonCode]
+		} else if d.err == nil {
+			d.err = offsetError(d.base+1, MalformedPacket)
+		}
+	}
+	c.Properties = d.properties()
+	d.end()
+	return c
+}
+
+func (d *packetDecoder) publish(h FixedHeader) *Publish {
+	c := &Publish{FixedHeader: h}
+	c.Dup = h.Flags&0x08 != 0
+	c.QoS = h.Flags >> 1 & 0x3
+	c.Retain = h.Flags&0x01 != 0
+	if c.QoS == 3 || (c.QoS == 0 && c.Dup) {
+		d.err = offsetError(h.Offset, MalformedPacket)
+		return nil
+	}
+	c.Topic = d.string()
+	if c.QoS > 0 {
+		c.PacketID = d.packetID()
+	}
+	c.Properties = d.properties()
+	if d.err != nil {
+		return nil
+	}
+	c.Payload = d.p.PeekRemainingBytes()
+	return c
+}
+
+// packetID reads a packet identifier, which must be non-zero
+func (d *packetDecoder) packetID() uint16 {
+	v := d.uint16()
+	if v == 0 && d.err == nil {
+		d.err = offsetError(d.base+d.p.Index()-2, ProtocolError)
+	}
+	return v
+}
+
+func (d *packetDecoder) subscribe(h FixedHeader) *Subscribe {
+	c := &Subscribe{FixedHeader: h}
+	c.PacketID = d.packetID()
+	c.Properties = d.properties()
+	for d.err == nil && d.p.RemainingLength() > 0 {
+		var s Subscription
+		s.TopicFilter = d.string()
+		optionsOffset := d.p.Index()
+		options := d.byte()
+		s.QoS = options & 0x03
+		s.NoLocal = options&0x04 != 0
+		s.RetainAsPublished = options&0x08 != 0
+		s.RetainHandling = options >> 4 & 0x03
+		reserved := options & 0xc0
+		if d.version < Version5 {
+			reserved = options & 0xfc
+		}
+		if d.err == nil && (s.QoS == 3 || s.RetainHandling == 3 || reserved != 0) {
+			d.err = offsetError(d.base+optionsOffset, MalformedPacket)
+		}
+		c.Subscriptions = append(c.Subscriptions, s)
+	}
+	if d.err == nil && len(c.Subscriptions) == 0 {
+		d.fail(ProtocolError)
+	}
+	return c
+}
+
+func (d *packetDecoder) suback(h FixedHeader) *Suback {
+	c := &Suback{FixedHeader: h}
+	c.PacketID = d.packetID()
+	c.Properties = d.properties()
+	for d.err == nil && d.p.RemainingLength() > 0 {
+		c.ReasonCodes = append(c.ReasonCodes, ReasonCode(d.byte()))
+	}
+	if d.err == nil && len(c.ReasonCodes) == 0 {
+		d.fail(ProtocolError)
+	}
+	return c
+}
+
+func (d *packetDecoder) disconnect(h FixedHeader) *Disconnect {
+	c := &Disconnect{FixedHeader: h}
+	// The reason code & properties can be left out when they are success & none
+	if d.version >= Version5 && d.p.RemainingLength() > 0 {
+		c.ReasonCode = ReasonCode(d.byte())
+		if d.p.RemainingLength() > 0 {
+			c.Properties = d.properties()
+		}
+	}
+	d.end()
+	return c
+}
@@ -0,0 +1,170 @@
+package mqtt
+
+import (
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestConnect311(t *testing.T) {
+	b := mustHex("101b" + "00044d515454" + "04" + "c2" + "003c" + "0003616263" + "000475736572" + "000470617373")
+	cp, err := NewReader(decoder.New(b)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, ok := cp.(*Connect)
+	if !ok {
+		t.Fatalf("expected *Connect got %T", cp)
+	}
+	if c.ProtocolName != "MQTT" || c.ProtocolVersion != Version311 || !c.CleanStart || c.KeepAlive != 60 ||
+		c.ClientID != "abc" || c.Will != nil || c.Username != "user" || string(c.Password) != "pass" ||
+		c.Properties != nil || c.RemainingLength != 27 {
+		t.Errorf("unexpected packet %+v", c)
+	}
+}
+
+func TestConnect5(t *testing.T) {
+	b := mustHex("101f" + "00044d515454" + "05" + "2e" + "000a" +
+		"05" + "110000003c" + // Session expiry interval
+		"0000" + // Empty client identifier
+		"05" + "1800000005" + // Will delay interval
+		"000174" + "00026869")
+	cp, err := NewReader(decoder.New(b)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cp.(*Connect)
+	if c.ProtocolVersion != Version5 || c.ClientID != "" || c.HasUsername || c.HasPassword ||
+		c.Properties.Int(SessionExpiryInterval, 0) != 60 {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	w := c.Will
+	if w == nil || w.QoS != 1 || !w.Retain || w.Topic != "t" || string(w.Payload) != "hi" ||
+		w.Properties.Int(WillDelayInterval, 0) != 5 {
+		t.Errorf("unexpected will %+v", w)
+	}
+}
+
+func TestConnack(t *testing.T) {
+	r := NewReader(decoder.New(mustHex("20020105" + "20020100")))
+	cp, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cp.(*Connack)
+	if !c.SessionPresent || c.ReasonCode != NotAuthorized || c.Err() != NotAuthorized {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	cp, _ = r.Next()
+	if c := cp.(*Connack); c.ReasonCode != Success || c.Err() != nil {
+		t.Errorf("unexpected packet %+v", c)
+	}
+
+	r = NewReader(decoder.New(mustHex("2006" + "0087" + "03" + "210014")))
+	r.Version = Version5
+	cp, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cp.(*Connack); c.ReasonCode != NotAuthorized || c.Properties.Int(ReceiveMaximum, 0) != 20 {
+		t.Errorf("unexpected packet %+v", c)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := mustHex("3316" + "0003612f62" + "000a" + "09" + "0101" + "2600016b000176" + "68656c6c6f" +
+		"3008" + "0003612f62" + "00" + "2121")
+	r := NewReader(decoder.New(b))
+	r.Version = Version5
+	cp, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cp.(*Publish)
+	if c.Dup || c.QoS != 1 || !c.Retain || c.Topic != "a/b" || c.PacketID != 10 || string(c.Payload) != "hello" {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	if c.Properties.Int(PayloadFormatIndicator, 0) != 1 || len(c.Properties.UserProperties()) != 1 {
+		t.Errorf("unexpected properties %+v", c.Properties)
+	}
+	cp, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cp.(*Publish); c.QoS != 0 || c.PacketID != 0 || string(c.Payload) != "!!" {
+		t.Errorf("unexpected packet %+v", c)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	r := NewReader(decoder.New(mustHex("820d" + "0001" + "00" + "0003612f23" + "1d" + "000162" + "00")))
+	r.Version = Version5
+	cp, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cp.(*Subscribe)
+	if c.PacketID != 1 || len(c.Subscriptions) != 2 {
+		t.Fatalf("unexpected packet %+v", c)
+	}
+	if s := c.Subscriptions[0]; s.TopicFilter != "a/#" || s.QoS != 1 || !s.NoLocal || !s.RetainAsPublished || s.RetainHandling != 1 {
+		t.Errorf("unexpected subscription %+v", s)
+	}
+	if s := c.Subscriptions[1]; s.TopicFilter != "b" || s.QoS != 0 || s.NoLocal {
+		t.Errorf("unexpected subscription %+v", s)
+	}
+
+	// MQTT 3.1.1 has no properties
+	cp, err = NewReader(decoder.New(mustHex("8206" + "0002" + "000162" + "02"))).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cp.(*Subscribe); c.PacketID != 2 || len(c.Subscriptions) != 1 || c.Subscriptions[0].QoS != 2 {
+		t.Errorf("unexpected packet %+v", c)
+	}
+}
+
+func TestSuback(t *testing.T) {
+	r := NewReader(decoder.New(mustHex("9005" + "0001" + "00" + "0180" + "d000")))
+	r.Version = Version5
+	packets, err := r.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := packets[0].(*Suback)
+	if c.PacketID != 1 || len(c.ReasonCodes) != 2 || c.ReasonCodes[0] != GrantedQoS1 || c.ReasonCodes[1] != UnspecifiedError {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	if _, ok := packets[1].(*Pingresp); !ok {
+		t.Errorf("expected PINGRESP got %T", packets[1])
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	r := NewReader(decoder.New(mustHex("e000" + "e00181" + "e0088e" + "06" + "1f0003627965")))
+	r.Version = Version5
+	packets, err := r.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := packets[0].(*Disconnect); c.ReasonCode != Success || c.Err() != nil {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	if c := packets[1].(*Disconnect); c.Err() != MalformedPacket {
+		t.Errorf("unexpected packet %+v", c)
+	}
+	c := packets[2].(*Disconnect)
+	if p, ok := c.Properties.Get(ReasonString); c.Err() != SessionTakenOver || !ok || p.String != "bye" {
+		t.Errorf("unexpected packet %+v", c)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	cp, err := NewReader(decoder.New(mustHex("40020001"))).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := cp.(*Raw); !ok || c.Type() != PUBACK || len(c.Body) != 2 {
+		t.Errorf("unexpected packet %+v", cp)
+	}
+}
@@ -0,0 +1,176 @@
+package mqtt
+
+// PropertyID identifies an MQTT 5 property
+type PropertyID uint8
+
+// Property identifiers
+const (
+	PayloadFormatIndicator          PropertyID = 0x01
+	MessageExpiryInterval           PropertyID = 0x02
+	ContentType                     PropertyID = 0x03
+	ResponseTopic                   PropertyID = 0x08
+	CorrelationData                 PropertyID = 0x09
+	SubscriptionIdentifier          PropertyID = 0x0b
+	SessionExpiryInterval           PropertyID = 0x11
+	AssignedClientIdentifier        PropertyID = 0x12
+	ServerKeepAlive                 PropertyID = 0x13
+	AuthenticationMethod            PropertyID = 0x15
+	AuthenticationData              PropertyID = 0x16
+	RequestProblemInformation       PropertyID = 0x17
+	WillDelayInterval               PropertyID = 0x18
+	RequestResponseInformation      PropertyID = 0x19
+	ResponseInformation             PropertyID = 0x1a
+	ServerReference                 PropertyID = 0x1c
+	ReasonString                    PropertyID = 0x1f
+	ReceiveMaximum                  PropertyID = 0x21
+	TopicAliasMaximum               PropertyID = 0x22
+	TopicAlias                      PropertyID = 0x23
+	MaximumQoS                      PropertyID = 0x24
+	RetainAvailable                 PropertyID = 0x25
+	UserProperty                    PropertyID = 0x26
+	MaximumPacketSize               PropertyID = 0x27
+	WildcardSubscriptionAvailable   PropertyID = 0x28
+	SubscriptionIdentifierAvailable PropertyID = 0x29
+	SharedSubscriptionAvailable     PropertyID = 0x2a
+)
+
+// Property value types
+const (
+	propByte = iota + 1
+	propUint16
+	propUint32
+	propVBI
+	propString
+	propBinary
+	propPair
+)
+
+var propertyTypes = map[PropertyID]int{
+	PayloadFormatIndicator:          propByte,
+	MessageExpiryInterval:           propUint32,
+	ContentType:                     propString,
+	ResponseTopic:                   propString,
+	CorrelationData:                 propBinary,
+	SubscriptionIdentifier:          propVBI,
+	SessionExpiryInterval:           propUint32,
+	AssignedClientIdentifier:        propString,
+	ServerKeepAlive:                 propUint16,
+	AuthenticationMethod:            propString,
+	AuthenticationData:              propBinary,
+	RequestProblemInformation:       propByte,
+	WillDelayInterval:               propUint32,
+	RequestResponseInformation:      propByte,
+	ResponseInformation:             propString,
+	ServerReference:                 propString,
+	ReasonString:                    propString,
+	ReceiveMaximum:                  propUint16,
+	TopicAliasMaximum:               propUint16,
+	TopicAlias:                      propUint16,
+	MaximumQoS:                      propByte,
+	RetainAvailable:                 propByte,
+	UserProperty:                    propPair,
+	MaximumPacketSize:               propUint32,
+	WildcardSubscriptionAvailable:   propByte,
+	SubscriptionIdentifierAvailable: propByte,
+	SharedSubscriptionAvailable:     propByte,
+}
+
+// Property is an MQTT 5 property
+type Property struct {
+	ID     PropertyID
+	Int    uint32 // The value of an integer property
+	String string // The value of a string property, or the name of a user property
+	Value  string // The value of a user property
+	Data   []byte // The value of a binary property, aliasing the packet's buffer
+}
+
+// Properties are the properties of a packet in the order they were sent
+type Properties []Property
+
+// Get returns the first property with the given ID
+func (props Properties) Get(id PropertyID) (Property, bool) {
+	for _, p := range props {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Int returns the value of an integer property, or the given default if it is not present
+func (props Properties) Int(id PropertyID, def uint32) uint32 {
+	if p, ok := props.Get(id); ok {
+		return p.Int
+	}
+	return def
+}
+
+// UserProperties returns the user properties as name & value pairs, names can be repeated
+func (props Properties) UserProperties() [][2]string {
+	var v [][2]string
+	for _, p := range props {
+		if p.ID == UserProperty {
+			v = append(v, [2]string{p.String, p.Value})
+		}
+	}
+	return v
+}
+
+// properties reads the properties of an MQTT 5 packet, or nothing for earlier versions
+func (d *packetDecoder) properties() Properties {
+	if d.version < Version5 || d.err != nil {
+		return nil
+	}
+	offset := d.p.Index()
+	length := d.vbi()
+	if d.err != nil {
+		return nil
+	}
+	sub := d.p.Sub(length)
+	if sub == nil {
+		d.err = offsetError(d.base+offset, MalformedPacket)
+		return nil
+	}
+	pd := &packetDecoder{p: sub, base: d.base + d.p.Index() - length, version: d.version}
+	var props Properties
+	seen := make(map[PropertyID]bool)
+	for sub.RemainingLength() > 0 && pd.err == nil {
+		idOffset := sub.Index()
+		id := PropertyID(pd.vbi())
+		typ, ok := propertyTypes[id]
+		if pd.err != nil {
+			break
+		}
+		if !ok {
+			pd.err = offsetError(pd.base+idOffset, MalformedPacket)
+			break
+		}
+		// Only user properties & subscription identifiers can be repeated
+		if seen[id] && id != UserProperty && id != SubscriptionIdentifier {
+			pd.err = offsetError(pd.base+idOffset, ProtocolError)
+			break
+		}
+		seen[id] = true
+		p := Property{ID: id}
+		switch typ {
+		case propByte:
+			p.Int = uint32(pd.byte())
+		case propUint16:
+			p.Int = uint32(pd.uint16())
+		case propUint32:
+			p.Int = pd.uint32()
+		case propVBI:
+			p.Int = uint32(pd.vbi())
+		case propString:
+			p.String = pd.string()
+		case propBinary:
+			p.Data = pd.binary()
+		case propPair:
+			p.String = pd.string()
+			p.Value = pd.string()
+		}
+		props = append(props, p)
+	}
+	d.err = pd.err
+	return props
+}
@@ -0,0 +1,79 @@
+package mqtt
+
+import (
+	"errors"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestProperties(t *testing.T) {
+	props := "" +
+		"0101" + // Payload format indicator
+		"0200000e10" + // Message expiry interval
+		"030005746578742f" + // Content type "text/"
+		"0800017a" + // Response topic "z"
+		"09000201ff" + // Correlation data
+		"0b8001" + // Subscription identifier 128
+		"0b02" + // Subscription identifier 2
+		"2300ff" + // Topic alias
+		"26000161000162" + "26000161000163" // User properties a=b & a=c
+	b := mustHex("30" + "32" + "000161" + "2e" + props)
+	r := NewReader(decoder.New(b))
+	r.Version = Version5
+	cp, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cp.(*Publish)
+	if len(c.Properties) != 10 {
+		t.Fatalf("expected 10 properties got %+v", c.Properties)
+	}
+	p := c.Properties
+	if p.Int(PayloadFormatIndicator, 0) != 1 || p.Int(MessageExpiryInterval, 0) != 3600 || p.Int(TopicAlias, 0) != 255 {
+		t.Errorf("unexpected integer properties %+v", p)
+	}
+	if v, _ := p.Get(ContentType); v.String != "text/" {
+		t.Errorf("unexpected content type %+v", v)
+	}
+	if v, _ := p.Get(CorrelationData); len(v.Data) != 2 || v.Data[1] != 0xff {
+		t.Errorf("unexpected correlation data %+v", v)
+	}
+	if p[5].Int != 128 || p[6].Int != 2 {
+		t.Errorf("unexpected subscription identifiers %+v %+v", p[5], p[6])
+	}
+	u := p.UserProperties()
+	if len(u) != 2 || u[0] != [2]string{"a", "b"} || u[1] != [2]string{"a", "c"} {
+		t.Errorf("unexpected user properties %v", u)
+	}
+	if p.Int(ReceiveMaximum, 65535) != 65535 {
+		t.Error("expected default receive maximum")
+	}
+	if len(c.Payload) != 0 {
+		t.Errorf("unexpected payload %v", c.Payload)
+	}
+}
+
+func TestPropertiesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		hex    string
+		err    error
+		offset int
+	}{
+		{"duplicate", "3008" + "000161" + "04" + "01010101", ProtocolError, 8},
+		{"unknown", "3005" + "000161" + "01" + "7f", MalformedPacket, 6},
+		{"length", "3005" + "000161" + "05" + "01", MalformedPacket, 5},
+		{"truncated value", "3006" + "000161" + "02" + "0200", MalformedPacket, 7},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.hex))
+		r := NewReader(p)
+		r.Version = Version5
+		_, err := r.Next()
+		var oe *decoder.OffsetError
+		if !errors.Is(err, test.err) || !errors.As(err, &oe) || oe.Offset != test.offset {
+			t.Errorf("%s: expected %v at %d got %v", test.name, test.err, test.offset, err)
+		}
+	}
+}
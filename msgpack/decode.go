@@ -0,0 +1,546 @@
+// Package msgpack reads MessagePack data using a *decoder.Packet, either item by item for streaming
+// over large maps & arrays, or as a whole into interface{} values, and writes it for generating fixtures.
+package msgpack
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrMalformed = errors.New("malformed msgpack")
+var ErrUnexpectedType = errors.New("unexpected type")
+var ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+var ErrMaxLength = errors.New("maximum length exceeded")
+var ErrUnhashableKey = errors.New("map key can not be used in a go map")
+
+// Default limits for untrusted input
+const (
+	DefaultMaxDepth  = 32
+	DefaultMaxLength = 1 << 20
+)
+
+// ExtTimestamp is the extension type of the timestamp extension
+const ExtTimestamp = -1
+
+// Type is the type of the next item
+type Type int
+
+// Item types
+const (
+	Invalid Type = iota
+	Nil
+	Bool
+	Int // Negative ints & signed formats
+	Uint
+	Float
+	Str
+	Bin
+	Array
+	Map
+	Ext
+)
+
+// Extension is an extension type item other than a timestamp
+type Extension struct {
+	Type int8
+	Data []byte
+}
+
+// Decoder reads MessagePack items from a packet. Errors are an *decoder.OffsetError with the offset of the item
+type Decoder struct {
+	p         *decoder.Packet
+	MaxDepth  int // The deepest nesting of arrays & maps allowed by Decode
+	MaxLength int // The largest string length or item count allowed
+}
+
+// NewDecoder returns a Decoder reading from the packet's current position, the packet's byte order
+// is left as it is
+func NewDecoder(p *decoder.Packet) *Decoder {
+	return &Decoder{
+		p:         p,
+		MaxDepth:  DefaultMaxDepth,
+		MaxLength: DefaultMaxLength,
+	}
+}
+
+// More returns true if there is data left to read
+func (d *Decoder) More() bool {
+	return d.p.RemainingLength() > 0
+}
+
+// PeekType returns the type of the next item without reading it
+func (d *Decoder) PeekType() (Type, error) {
+	b, err := d.p.PeekByte()
+	if err != nil {
+		return Invalid, &decoder.OffsetError{Offset: d.p.Index(), Err: err}
+	}
+	t := typeOf(b)
+	if t == Invalid {
+		return Invalid, &decoder.OffsetError{Offset: d.p.Index(), Err: ErrMalformed}
+	}
+	return t, nil
+}
+
+func typeOf(b byte) Type {
+	switch {
+	case b <= 0x7f:
+		return Uint
+	case b <= 0x8f:
+		return Map
+	case b <= 0x9f:
+		return Array
+	case b <= 0xbf:
+		return Str
+	case b >= 0xe0:
+		return Int
+	}
+	switch b {
+	case 0xc0:
+		return Nil
+	case 0xc2, 0xc3:
+		return Bool
+	case 0xc4, 0xc5, 0xc6:
+		return Bin
+	case 0xc7, 0xc8, 0xc9, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return Ext
+	case 0xca, 0xcb:
+		return Float
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return Uint
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		return Int
+	case 0xd9, 0xda, 0xdb:
+		return Str
+	case 0xdc, 0xdd:
+		return Array
+	case 0xde, 0xdf:
+		return Map
+	}
+	return Invalid
+}
+
+// start reads the format byte of an item checking it is the wanted type
+func (d *Decoder) start(want Type) (byte, int, error) {
+	offset := d.p.Index()
+	t, err := d.PeekType()
+	if err != nil {
+		return 0, offset, err
+	}
+	if t != want && !(want == Int && t == Uint) {
+		return 0, offset, &decoder.OffsetError{Offset: offset, Err: ErrUnexpectedType}
+	}
+	return d.p.Byte(), offset, nil
+}
+
+// done checks the packet for errors after reading an item
+func (d *Decoder) done(offset int) error {
+	if d.p.Err != nil {
+		err := d.p.Err
+		d.p.Err = nil
+		d.p.SeekTo(offset)
+		return &decoder.OffsetError{Offset: offset, Err: err}
+	}
+	return nil
+}
+
+// ReadNil reads a nil
+func (d *Decoder) ReadNil() error {
+	_, _, err := d.start(Nil)
+	return err
+}
+
+// ReadBool reads a boolean
+func (d *Decoder) ReadBool() (bool, error) {
+	b, _, err := d.start(Bool)
+	return b == 0xc3, err
+}
+
+// ReadInt reads any int format as an int64, uint64 values too large for an int64 are an error
+func (d *Decoder) ReadInt() (int64, error) {
+	b, offset, err := d.start(Int)
+	if err != nil {
+		return 0, err
+	}
+	var v int64
+	switch {
+	case b <= 0x7f:
+		v = int64(b)
+	case b >= 0xe0:
+		v = int64(int8(b))
+	case b == 0xcc:
+		v = int64(d.p.Byte())
+	case b == 0xcd:
+		v = int64(d.uint16())
+	case b == 0xce:
+		v = int64(d.uint32())
+	case b == 0xcf:
+		u := d.uint64()
+		if u > math.MaxInt64 {
+			d.p.SeekTo(offset)
+			return 0, &decoder.OffsetError{Offset: offset, Err: ErrUnexpectedType}
+		}
+		v = int64(u)
+	case b == 0xd0:
+		v = int64(int8(d.p.Byte()))
+	case b == 0xd1:
+		v = int64(int16(d.uint16()))
+	case b == 0xd2:
+		v = int64(int32(d.uint32()))
+	case b == 0xd3:
+		v = int64(d.uint64())
+	}
+	return v, d.done(offset)
+}
+
+// ReadUint reads an unsigned int format as a uint64
+func (d *Decoder) ReadUint() (uint64, error) {
+	b, offset, err := d.start(Uint)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	switch b {
+	case 0xcc:
+		v = uint64(d.p.Byte())
+	case 0xcd:
+		v = uint64(d.uint16())
+	case 0xce:
+		v = uint64(d.uint32())
+	case 0xcf:
+		v = d.uint64()
+	default:
+		v = uint64(b)
+	}
+	return v, d.done(offset)
+}
+
+// ReadFloat reads a float32 or float64 as a float64
+func (d *Decoder) ReadFloat() (float64, error) {
+	b, offset, err := d.start(Float)
+	if err != nil {
+		return 0, err
+	}
+	var v float64
+	if b == 0xca {
+		v = float64(math.Float32frombits(d.uint32()))
+	} else {
+		v = math.Float64frombits(d.uint64())
+	}
+	return v, d.done(offset)
+}
+
+// ReadString reads a string
+func (d *Decoder) ReadString() (string, error) {
+	b, err := d.ReadStringView()
+	return string(b), err
+}
+
+// ReadStringView reads a string as bytes aliasing the packet's buffer
+func (d *Decoder) ReadStringView() ([]byte, error) {
+	b, offset, err := d.start(Str)
+	if err != nil {
+		return nil, err
+	}
+	var l int
+	switch b {
+	case 0xd9:
+		l = int(d.p.Byte())
+	case 0xda:
+		l = int(d.uint16())
+	case 0xdb:
+		l = int(d.uint32())
+	default:
+		l = int(b & 0x1f)
+	}
+	return d.bytes(offset, l)
+}
+
+// ReadBytes reads a bin item as bytes aliasing the packet's buffer
+func (d *Decoder) ReadBytes() ([]byte, error) {
+	b, offset, err := d.start(Bin)
+	if err != nil {
+		return nil, err
+	}
+	var l int
+	switch b {
+	case 0xc4:
+		l = int(d.p.Byte())
+	case 0xc5:
+		l = int(d.uint16())
+	case 0xc6:
+		l = int(d.uint32())
+	}
+	return d.bytes(offset, l)
+}
+
+// uint16 reads a big endian uint16 whatever the packet's byte order
+func (d *Decoder) uint16() uint16 {
+	defer d.p.SetByteOrder(d.p.ByteOrder())
+	d.p.SetBigEndian()
+	return d.p.Uint16()
+}
+
+// uint32 reads a big endian uint32 whatever the packet's byte order
+func (d *Decoder) uint32() uint32 {
+	defer d.p.SetByteOrder(d.p.ByteOrder())
+	d.p.SetBigEndian()
+	return d.p.Uint32()
+}
+
+// uint64 reads a big endian uint64 whatever the packet's byte order
+func (d *Decoder) uint64() uint64 {
+	defer d.p.SetByteOrder(d.p.ByteOrder())
+	d.p.SetBigEndian()
+	return d.p.Uint64()
+}
+
+func (d *Decoder) bytes(offset int, l int) ([]byte, error) {
+	if err := d.checkLength(offset, l); err != nil {
+		return nil, err
+	}
+	v := d.p.Bytes(l)
+	return v, d.done(offset)
+}
+
+// ReadArrayHeader reads the start of an array returning the number of items that follow it
+func (d *Decoder) ReadArrayHeader() (int, error) {
+	b, offset, err := d.start(Array)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	switch b {
+	case 0xdc:
+		n = int(d.uint16())
+	case 0xdd:
+		n = int(d.uint32())
+	default:
+		n = int(b & 0x0f)
+	}
+	return n, d.count(offset, n)
+}
+
+// ReadMapHeader reads the start of a map returning the number of key/value pairs that follow it
+func (d *Decoder) ReadMapHeader() (int, error) {
+	b, offset, err := d.start(Map)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	switch b {
+	case 0xde:
+		n = int(d.uint16())
+	case 0xdf:
+		n = int(d.uint32())
+	default:
+		n = int(b & 0x0f)
+	}
+	return n, d.count(offset, n)
+}
+
+// count checks an item count, every item takes at least one byte
+func (d *Decoder) count(offset int, n int) error {
+	if err := d.done(offset); err != nil {
+		return err
+	}
+	if n > d.MaxLength {
+		d.p.SeekTo(offset)
+		return &decoder.OffsetError{Offset: offset, Err: ErrMaxLength}
+	}
+	if n > d.p.RemainingLength() {
+		d.p.SeekTo(offset)
+		return &decoder.OffsetError{Offset: offset, Err: decoder.ErrReadPastEndData}
+	}
+	return nil
+}
+
+func (d *Decoder) checkLength(offset int, l int) error {
+	if err := d.done(offset); err != nil {
+		return err
+	}
+	if l > d.MaxLength {
+		d.p.SeekTo(offset)
+		return &decoder.OffsetError{Offset: offset, Err: ErrMaxLength}
+	}
+	return nil
+}
+
+// ReadExt reads an extension item, the data aliases the packet's buffer
+func (d *Decoder) ReadExt() (Extension, error) {
+	b, offset, err := d.start(Ext)
+	if err != nil {
+		return Extension{}, err
+	}
+	var l int
+	switch b {
+	case 0xc7:
+		l = int(d.p.Byte())
+	case 0xc8:
+		l = int(d.uint16())
+	case 0xc9:
+		l = int(d.uint32())
+	default: // fixext 1, 2, 4, 8 & 16
+		l = 1 << (b - 0xd4)
+	}
+	t := int8(d.p.Byte())
+	data, err := d.bytes(offset, l)
+	if err != nil {
+		return Extension{}, err
+	}
+	return Extension{Type: t, Data: data}, nil
+}
+
+// ReadTime reads a timestamp extension
+func (d *Decoder) ReadTime() (time.Time, error) {
+	offset := d.p.Index()
+	e, err := d.ReadExt()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := timestamp(e)
+	if err != nil {
+		d.p.SeekTo(offset)
+		return time.Time{}, &decoder.OffsetError{Offset: offset, Err: err}
+	}
+	return t, nil
+}
+
+func timestamp(e Extension) (time.Time, error) {
+	if e.Type != ExtTimestamp {
+		return time.Time{}, ErrUnexpectedType
+	}
+	p := decoder.New(e.Data)
+	switch len(e.Data) {
+	case 4:
+		return time.Unix(int64(p.Uint32()), 0).UTC(), nil
+	case 8:
+		v := p.Uint64()
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := p.Uint32()
+		return time.Unix(int64(p.Uint64()), int64(nsec)).UTC(), nil
+	}
+	return time.Time{}, ErrMalformed
+}
+
+// Skip reads past the next item, including all the items within an array or map
+func (d *Decoder) Skip() error {
+	_, err := d.decode(0, true)
+	return err
+}
+
+// Decode reads the next item returning nil, bool, int64 (for negative values & signed formats), uint64,
+// float64, string, []byte, []interface{}, map[interface{}]interface{}, time.Time or Extension
+func (d *Decoder) Decode() (interface{}, error) {
+	return d.decode(0, false)
+}
+
+func (d *Decoder) decode(depth int, skip bool) (interface{}, error) {
+	offset := d.p.Index()
+	t, err := d.PeekType()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case Nil:
+		return nil, d.ReadNil()
+	case Bool:
+		return d.ReadBool()
+	case Int:
+		return d.ReadInt()
+	case Uint:
+		return d.ReadUint()
+	case Float:
+		return d.ReadFloat()
+	case Str:
+		if skip {
+			_, err := d.ReadStringView()
+			return nil, err
+		}
+		return d.ReadString()
+	case Bin:
+		b, err := d.ReadBytes()
+		if skip || err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case Ext:
+		e, err := d.ReadExt()
+		if skip || err != nil {
+			return nil, err
+		}
+		if e.Type == ExtTimestamp {
+			t, err := timestamp(e)
+			if err != nil {
+				return nil, &decoder.OffsetError{Offset: offset, Err: err}
+			}
+			return t, nil
+		}
+		e.Data = append([]byte{}, e.Data...)
+		return e, nil
+	}
+
+	if depth >= d.MaxDepth {
+		return nil, &decoder.OffsetError{Offset: offset, Err: ErrMaxDepth}
+	}
+	if t == Array {
+		n, err := d.ReadArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		var v []interface{}
+		if !skip {
+			v = make([]interface{}, 0, n)
+		}
+		for i := 0; i < n; i++ {
+			x, err := d.decode(depth+1, skip)
+			if err != nil {
+				return nil, err
+			}
+			if !skip {
+				v = append(v, x)
+			}
+		}
+		if skip {
+			return nil, nil
+		}
+		return v, nil
+	}
+
+	n, err := d.ReadMapHeader()
+	if err != nil {
+		return nil, err
+	}
+	var v map[interface{}]interface{}
+	if !skip {
+		v = make(map[interface{}]interface{}, n)
+	}
+	for i := 0; i < n; i++ {
+		keyOffset := d.p.Index()
+		k, err := d.decode(depth+1, skip)
+		if err != nil {
+			return nil, err
+		}
+		x, err := d.decode(depth+1, skip)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			continue
+		}
+		switch key := k.(type) {
+		case []byte:
+			k = string(key)
+		case []interface{}, map[interface{}]interface{}, Extension:
+			return nil, &decoder.OffsetError{Offset: keyOffset, Err: ErrUnhashableKey}
+		}
+		v[k] = x
+	}
+	if skip {
+		return nil, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,313 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Encoder writes MessagePack items using the smallest format for each value
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewEncoder returns an Encoder writing to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Marshal returns the MessagePack encoding of v, see Encoder.Encode
+func Marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	err := NewEncoder(&b).Encode(v)
+	return b.Bytes(), err
+}
+
+// write sends the bytes to the writer, keeping the first error
+func (e *Encoder) write(b ...byte) error {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+	return e.err
+}
+
+// head writes a format byte followed by a big endian value of the given number of bytes
+func (e *Encoder) head(format byte, size int, v uint64) error {
+	e.buf = append(e.buf[:0], format)
+	for i := size - 1; i >= 0; i-- {
+		e.buf = append(e.buf, byte(v>>(uint(i)*8)))
+	}
+	return e.write(e.buf...)
+}
+
+// WriteNil writes a nil
+func (e *Encoder) WriteNil() error {
+	return e.write(0xc0)
+}
+
+// WriteBool writes a boolean
+func (e *Encoder) WriteBool(v bool) error {
+	if v {
+		return e.write(0xc3)
+	}
+	return e.write(0xc2)
+}
+
+// WriteInt writes a signed int, using an unsigned format for positive values
+func (e *Encoder) WriteInt(v int64) error {
+	switch {
+	case v >= 0:
+		return e.WriteUint(uint64(v))
+	case v >= -32:
+		return e.write(byte(v))
+	case v >= math.MinInt8:
+		return e.head(0xd0, 1, uint64(v))
+	case v >= math.MinInt16:
+		return e.head(0xd1, 2, uint64(v))
+	case v >= math.MinInt32:
+		return e.head(0xd2, 4, uint64(v))
+	}
+	return e.head(0xd3, 8, uint64(v))
+}
+
+// WriteUint writes an unsigned int
+func (e *Encoder) WriteUint(v uint64) error {
+	switch {
+	case v <= 0x7f:
+		return e.write(byte(v))
+	case v <= math.MaxUint8:
+		return e.head(0xcc, 1, v)
+	case v <= math.MaxUint16:
+		return e.head(0xcd, 2, v)
+	case v <= math.MaxUint32:
+		return e.head(0xce, 4, v)
+	}
+	return e.head(0xcf, 8, v)
+}
+
+// WriteFloat32 writes a float32
+func (e *Encoder) WriteFloat32(v float32) error {
+	return e.head(0xca, 4, uint64(math.Float32bits(v)))
+}
+
+// WriteFloat64 writes a float64
+func (e *Encoder) WriteFloat64(v float64) error {
+	return e.head(0xcb, 8, math.Float64bits(v))
+}
+
+// WriteString writes a string
+func (e *Encoder) WriteString(v string) error {
+	l := uint64(len(v))
+	switch {
+	case l <= 31:
+		e.write(0xa0 | byte(l))
+	case l <= math.MaxUint8:
+		e.head(0xd9, 1, l)
+	case l <= math.MaxUint16:
+		e.head(0xda, 2, l)
+	default:
+		e.head(0xdb, 4, l)
+	}
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, v)
+	}
+	return e.err
+}
+
+// WriteBytes writes a bin item
+func (e *Encoder) WriteBytes(v []byte) error {
+	l := uint64(len(v))
+	switch {
+	case l <= math.MaxUint8:
+		e.head(0xc4, 1, l)
+	case l <= math.MaxUint16:
+		e.head(0xc5, 2, l)
+	default:
+		e.head(0xc6, 4, l)
+	}
+	return e.write(v...)
+}
+
+// WriteArrayHeader writes the start of an array of n items, which must be written next
+func (e *Encoder) WriteArrayHeader(n int) error {
+	switch {
+	case n <= 15:
+		return e.write(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		return e.head(0xdc, 2, uint64(n))
+	}
+	return e.head(0xdd, 4, uint64(n))
+}
+
+// WriteMapHeader writes the start of a map of n key/value pairs, which must be written next
+func (e *Encoder) WriteMapHeader(n int) error {
+	switch {
+	case n <= 15:
+		return e.write(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		return e.head(0xde, 2, uint64(n))
+	}
+	return e.head(0xdf, 4, uint64(n))
+}
+
+// WriteExt writes an extension item
+func (e *Encoder) WriteExt(x Extension) error {
+	l := len(x.Data)
+	switch l {
+	case 1:
+		e.write(0xd4)
+	case 2:
+		e.write(0xd5)
+	case 4:
+		e.write(0xd6)
+	case 8:
+		e.write(0xd7)
+	case 16:
+		e.write(0xd8)
+	default:
+		switch {
+		case l <= math.MaxUint8:
+			e.head(0xc7, 1, uint64(l))
+		case l <= math.MaxUint16:
+			e.head(0xc8, 2, uint64(l))
+		default:
+			e.head(0xc9, 4, uint64(l))
+		}
+	}
+	e.write(byte(x.Type))
+	return e.write(x.Data...)
+}
+
+// WriteTime writes a timestamp extension using the smallest of the 32, 64 & 96 bit formats
+func (e *Encoder) WriteTime(t time.Time) error {
+	sec, nsec := t.Unix(), t.Nanosecond()
+	var data []byte
+	switch {
+	case sec >= 0 && sec <= math.MaxUint32 && nsec == 0:
+		data = make([]byte, 4)
+		binary.BigEndian.PutUint32(data, uint32(sec))
+	case sec >= 0 && sec < 1<<34:
+		data = make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(nsec)<<34|uint64(sec))
+	default:
+		data = make([]byte, 12)
+		binary.BigEndian.PutUint32(data, uint32(nsec))
+		binary.BigEndian.PutUint64(data[4:], uint64(sec))
+	}
+	return e.WriteExt(Extension{Type: ExtTimestamp, Data: data})
+}
+
+// Encode writes v, which may be nil, a bool, any int, uint or float, a string, []byte, time.Time,
+// Extension, or a slice, array, map or struct of them. Maps are written in key order so the output
+// is repeatable, and struct fields use their `msgpack:"name"` tag or name, `msgpack:"-"` skips a field
+func (e *Encoder) Encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		return e.WriteNil()
+	case []byte:
+		return e.WriteBytes(v)
+	case time.Time:
+		return e.WriteTime(v)
+	case Extension:
+		return e.WriteExt(v)
+	}
+	return e.encode(reflect.ValueOf(v))
+}
+
+func (e *Encoder) encode(rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Bool:
+		return e.WriteBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.WriteInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.WriteUint(rv.Uint())
+	case reflect.Float32:
+		return e.WriteFloat32(float32(rv.Float()))
+	case reflect.Float64:
+		return e.WriteFloat64(rv.Float())
+	case reflect.String:
+		return e.WriteString(rv.String())
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return e.WriteNil()
+		}
+		return e.Encode(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return e.WriteNil()
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return e.WriteBytes(b)
+		}
+		e.WriteArrayHeader(rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return e.err
+	case reflect.Map:
+		if rv.IsNil() {
+			return e.WriteNil()
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		e.WriteMapHeader(len(keys))
+		for _, k := range keys {
+			if err := e.Encode(k.Interface()); err != nil {
+				return err
+			}
+			if err := e.Encode(rv.MapIndex(k).Interface()); err != nil {
+				return err
+			}
+		}
+		return e.err
+	case reflect.Struct:
+		return e.encodeStruct(rv)
+	}
+	return fmt.Errorf("msgpack: can not encode %s", rv.Type())
+}
+
+func (e *Encoder) encodeStruct(rv reflect.Value) error {
+	t := rv.Type()
+	var names []string
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // Unexported
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("msgpack"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		names = append(names, name)
+		fields = append(fields, i)
+	}
+	e.WriteMapHeader(len(fields))
+	for i, n := range names {
+		e.WriteString(n)
+		if err := e.Encode(rv.Field(fields[i]).Interface()); err != nil {
+			return err
+		}
+	}
+	return e.err
+}
@@ -0,0 +1,203 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		encoded string
+		decoded interface{}
+	}{
+		{nil, "c0", nil},
+		{false, "c2", false},
+		{true, "c3", true},
+		{0, "00", uint64(0)},
+		{127, "7f", uint64(127)},
+		{128, "cc80", uint64(128)},
+		{65535, "cdffff", uint64(65535)},
+		{65536, "ce00010000", uint64(65536)},
+		{uint64(math.MaxUint64), "cfffffffffffffffff", uint64(math.MaxUint64)},
+		{-1, "ff", int64(-1)},
+		{-32, "e0", int64(-32)},
+		{-33, "d0df", int64(-33)},
+		{-129, "d1ff7f", int64(-129)},
+		{-32769, "d2ffff7fff", int64(-32769)},
+		{int64(math.MinInt64), "d38000000000000000", int64(math.MinInt64)},
+		{float32(1.5), "ca3fc00000", float64(1.5)},
+		{1.5, "cb3ff8000000000000", 1.5},
+		{"", "a0", ""},
+		{"abc", "a3616263", "abc"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32), strings.Repeat("x", 32)},
+		{[]byte{1, 2}, "c4020102", []byte{1, 2}},
+		{[]int{1, -1}, "9201ff", []interface{}{uint64(1), int64(-1)}},
+		{map[string]int{"b": 2, "a": 1}, "82a16101a16202", map[interface{}]interface{}{"a": uint64(1), "b": uint64(2)}},
+		{Extension{Type: 5, Data: []byte{1}}, "d40501", Extension{Type: 5, Data: []byte{1}}},
+		{Extension{Type: 5, Data: []byte{1, 2, 3}}, "c70305010203", Extension{Type: 5, Data: []byte{1, 2, 3}}},
+		{time.Unix(1, 0).UTC(), "d6ff00000001", time.Unix(1, 0).UTC()},
+		{time.Unix(1, 5).UTC(), "d7ff0000001400000001", time.Unix(1, 5).UTC()},
+		{time.Unix(-1, 5).UTC(), "c70cff00000005ffffffffffffffff", time.Unix(-1, 5).UTC()},
+	}
+
+	for _, test := range tests {
+		b, err := Marshal(test.value)
+		if err != nil {
+			t.Errorf("%v: got unexpected err: %s", test.value, err)
+			continue
+		}
+		if h := hex.EncodeToString(b); h != test.encoded {
+			t.Errorf("%v: expected %s got %s", test.value, test.encoded, h)
+		}
+		p := decoder.New(b)
+		v, err := NewDecoder(p).Decode()
+		if err != nil {
+			t.Errorf("%s: got unexpected err: %s", test.encoded, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.decoded) {
+			t.Errorf("%s: expected %#v got %#v", test.encoded, test.decoded, v)
+		}
+		if !p.EOF() {
+			t.Errorf("%s: expected EOF at %d", test.encoded, p.Index())
+		}
+	}
+}
+
+type fixture struct {
+	ID      string            `msgpack:"id"`
+	Values  []float64         `msgpack:"values"`
+	Meta    map[string]string `msgpack:"meta"`
+	Large   []uint8           `msgpack:"large"`
+	Ignored int               `msgpack:"-"`
+}
+
+func TestStreaming(t *testing.T) {
+	b, err := Marshal(fixture{
+		ID:     "dev1",
+		Values: []float64{1.5, 2.5},
+		Meta:   map[string]string{"fw": "1.2"},
+		Large:  make([]uint8, 300),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDecoder(decoder.New(b))
+	n, err := d.ReadMapHeader()
+	if err != nil || n != 4 {
+		t.Fatalf("expected 4 pairs got %d err %v", n, err)
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		key, err := d.ReadString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch key {
+		case "id":
+			if id, err := d.ReadString(); id != "dev1" || err != nil {
+				t.Errorf("expected dev1 got %s err %v", id, err)
+			}
+		case "values":
+			l, err := d.ReadArrayHeader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j := 0; j < l; j++ {
+				f, err := d.ReadFloat()
+				if err != nil {
+					t.Fatal(err)
+				}
+				sum += f
+			}
+		default:
+			if err := d.Skip(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if sum != 4 {
+		t.Errorf("expected sum of 4 got %f", sum)
+	}
+	if d.More() {
+		t.Error("expected no more data")
+	}
+}
+
+func TestDecodeKeepsByteOrder(t *testing.T) {
+	p := decoder.New([]byte{0xcd, 0x01, 0x02, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0x03, 0x04})
+	p.SetLittleEndian()
+	d := NewDecoder(p)
+	if v, err := d.ReadUint(); err != nil || v != 0x0102 {
+		t.Errorf("expected 0x0102 got %v err %v", v, err)
+	}
+	if v, err := d.ReadFloat(); err != nil || v != 1.5 {
+		t.Errorf("expected 1.5 got %v err %v", v, err)
+	}
+	if v := p.Uint16(); v != 0x0403 {
+		t.Errorf("expected the packet to still be little endian got 0x%04X", v)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		err    error
+	}{
+		{"c1", 0, ErrMalformed},
+		{"cd01", 0, decoder.ErrReadPastEndData},
+		{"92c0", 0, decoder.ErrReadPastEndData},
+		{"93c0c0cd", 3, decoder.ErrReadPastEndData},
+		{"dbffffffff", 0, ErrMaxLength},
+		{"8191c0c0", 1, ErrUnhashableKey},
+		{"919191c0", 2, ErrMaxDepth},
+		{"d5ff0000", 0, ErrMalformed},
+	}
+	for _, test := range tests {
+		b, _ := hex.DecodeString(test.input)
+		d := NewDecoder(decoder.New(b))
+		d.MaxDepth = 2
+		d.MaxLength = 1 << 16
+		_, err := d.Decode()
+		var e *decoder.OffsetError
+		if !errors.As(err, &e) || e.Offset != test.offset || !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v at %d got %v", test.input, test.err, test.offset, err)
+		}
+	}
+
+	// A type mismatch leaves the decoder on the item
+	p := decoder.New([]byte{0xa1, 'x'})
+	d := NewDecoder(p)
+	if _, err := d.ReadInt(); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("expected ErrUnexpectedType got %v", err)
+	}
+	if s, err := d.ReadString(); s != "x" || err != nil {
+		t.Errorf("expected x got %s err %v", s, err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	e.WriteArrayHeader(2)
+	e.WriteInt(-200)
+	e.WriteBytes(bytes.Repeat([]byte{1}, 256))
+	if h := hex.EncodeToString(b.Bytes()[:7]); h != "92d1ff38c50100" {
+		t.Errorf("expected 92d1ff38c50100 got %s", h)
+	}
+
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Error("expected error encoding a channel")
+	}
+}
@@ -0,0 +1,166 @@
+package pcap
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+	"github.com/kgolding/go-decoder/layers"
+)
+
+// ErrNoPayload is returned by Record.Payload for frames without a UDP or TCP payload
+var ErrNoPayload = errors.New("no UDP or TCP payload")
+
+// Payload is the UDP or TCP payload of a captured frame
+type Payload struct {
+	Timestamp time.Time
+	Protocol  layers.Protocol
+	Src       net.IP
+	Dst       net.IP
+	SrcPort   uint16
+	DstPort   uint16
+	Packet    *decoder.Packet // The payload, set to big endian
+	Offset    int             // The offset of the record or block in the file
+
+	// The decoded headers, nil if not present
+	Ethernet *layers.Ethernet
+	IPv4     *layers.IPv4
+	IPv6     *layers.IPv6
+	UDP      *layers.UDP
+	TCP      *layers.TCP
+}
+
+// NextPayload returns the UDP or TCP payload of the next captured frame that has one, skipping other frames,
+// or io.EOF at the end of the file. If a frame's headers are invalid the error is returned as an
+// *decoder.OffsetError with the offset of its record, and reading can continue with the next frame
+func (r *Reader) NextPayload() (Payload, error) {
+	for {
+		rec, err := r.Next()
+		if err != nil {
+			return Payload{}, err
+		}
+		pl, err := rec.Payload()
+		if err == ErrNoPayload {
+			continue
+		}
+		if err != nil {
+			return Payload{}, &decoder.OffsetError{Offset: rec.Offset, Err: err}
+		}
+		return pl, nil
+	}
+}
+
+// All returns the payloads of all the remaining frames
+func (r *Reader) All() ([]Payload, error) {
+	var v []Payload
+	for {
+		pl, err := r.NextPayload()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return v, err
+		}
+		v = append(v, pl)
+	}
+}
+
+// Payload strips the link, IP & UDP or TCP headers from the frame and returns its payload. ErrNoPayload is
+// returned for frames that are not UDP or TCP over IP, are IP fragments or have an empty payload.
+// Checksums are not verified, as captures on the sending host often have them left to the network card
+func (rec Record) Payload() (Payload, error) {
+	p := decoder.New(rec.Data)
+	pl := Payload{Timestamp: rec.Timestamp, Offset: rec.Offset}
+	var etherType layers.EtherType
+	switch rec.LinkType {
+	case LinkTypeEthernet:
+		eth, payload, err := layers.DecodeEthernet(p)
+		if err != nil {
+			return Payload{}, err
+		}
+		pl.Ethernet = &eth
+		etherType = eth.EtherType
+		p = payload
+	case LinkTypeNull:
+		// The address family is in the capturing host's byte order
+		family := p.Uint32()
+		if p.Err != nil {
+			return Payload{}, p.Err
+		}
+		if family > 0xffff {
+			family = swap32(family)
+		}
+		switch family {
+		case 2:
+			etherType = layers.EtherTypeIPv4
+		case 10, 24, 28, 30:
+			etherType = layers.EtherTypeIPv6
+		}
+	case LinkTypeRaw:
+		switch b, _ := p.PeekN(1); {
+		case len(b) == 1 && b[0]>>4 == 4:
+			etherType = layers.EtherTypeIPv4
+		case len(b) == 1 && b[0]>>4 == 6:
+			etherType = layers.EtherTypeIPv6
+		}
+	case LinkTypeIPv4:
+		etherType = layers.EtherTypeIPv4
+	case LinkTypeIPv6:
+		etherType = layers.EtherTypeIPv6
+	}
+
+	switch etherType {
+	case layers.EtherTypeIPv4:
+		h, payload, err := layers.DecodeIPv4(p)
+		if err != nil && !errors.Is(err, layers.ErrChecksum) {
+			return Payload{}, err
+		}
+		if h.IsFragment() {
+			return Payload{}, ErrNoPayload
+		}
+		pl.IPv4 = &h
+		pl.Protocol, pl.Src, pl.Dst = h.Protocol, h.Src, h.Dst
+		p = payload
+	case layers.EtherTypeIPv6:
+		h, payload, err := layers.DecodeIPv6(p)
+		if err != nil {
+			return Payload{}, err
+		}
+		if h.IsFragment() {
+			return Payload{}, ErrNoPayload
+		}
+		pl.IPv6 = &h
+		pl.Protocol, pl.Src, pl.Dst = h.Protocol, h.Src, h.Dst
+		p = payload
+	default:
+		return Payload{}, ErrNoPayload
+	}
+
+	switch pl.Protocol {
+	case layers.ProtocolUDP:
+		h, payload, err := layers.DecodeUDP(p)
+		if err != nil {
+			return Payload{}, err
+		}
+		pl.UDP = &h
+		pl.SrcPort, pl.DstPort = h.SrcPort, h.DstPort
+		p = payload
+	case layers.ProtocolTCP:
+		h, payload, err := layers.DecodeTCP(p)
+		if err != nil {
+			return Payload{}, err
+		}
+		pl.TCP = &h
+		pl.SrcPort, pl.DstPort = h.SrcPort, h.DstPort
+		p = payload
+	default:
+		return Payload{}, ErrNoPayload
+	}
+	if p.RemainingLength() == 0 {
+		return Payload{}, ErrNoPayload
+	}
+	pl.Packet = p
+	return pl, nil
+}
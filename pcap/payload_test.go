@@ -0,0 +1,166 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+	"github.com/kgolding/go-decoder/layers"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	ethernetIPv4 = mustHex("ffffffffffff" + "001122334455" + "0800")
+	ethernetVLAN = mustHex("ffffffffffff" + "001122334455" + "8100" + "0064" + "86dd")
+	ethernetARP  = mustHex("ffffffffffff" + "001122334455" + "0806")
+)
+
+// ipv4 returns an IPv4 header for the payload
+func ipv4(proto layers.Protocol, flags uint16, payload []byte) []byte {
+	b := mustHex("4500000000010000" + "4000" + "0000" + "c0a80001" + "c0a80002")
+	binary.BigEndian.PutUint16(b[2:], uint16(20+len(payload)))
+	binary.BigEndian.PutUint16(b[6:], flags)
+	b[9] = byte(proto)
+	return append(b, payload...)
+}
+
+// ipv6 returns an IPv6 header with a destination options extension header for the payload
+func ipv6(proto layers.Protocol, payload []byte) []byte {
+	b := mustHex("60000000" + "0000" + "3c" + "40" +
+		"20010db8000000000000000000000001" + "20010db8000000000000000000000002" +
+		"00" + "00" + "010400000000") // Destination options with a PadN option
+	binary.BigEndian.PutUint16(b[4:], uint16(8+len(payload)))
+	b[40] = byte(proto)
+	return append(b, payload...)
+}
+
+func udp(payload string) []byte {
+	b := mustHex("3039" + "0035" + "0000" + "0000")
+	binary.BigEndian.PutUint16(b[4:], uint16(8+len(payload)))
+	return append(b, payload...)
+}
+
+func tcp(payload string) []byte {
+	// Data offset of 6 for a 4 byte MSS option
+	b := mustHex("c000" + "0050" + "00000001" + "00000000" + "6018" + "ffff" + "0000" + "0000" + "020405b4")
+	return append(b, payload...)
+}
+
+func join(b ...[]byte) []byte {
+	var v []byte
+	for _, x := range b {
+		v = append(v, x...)
+	}
+	return v
+}
+
+func TestPayloads(t *testing.T) {
+	frames := [][]byte{
+		join(ethernetIPv4, ipv4(layers.ProtocolUDP, 0x4000, udp("hello")), []byte{0, 0, 0, 0}), // With padding
+		join(ethernetARP, make([]byte, 28)),
+		join(ethernetVLAN, ipv6(layers.ProtocolTCP, tcp("GET /"))),
+		join(ethernetIPv4, ipv4(layers.ProtocolTCP, 0, tcp(""))), // An ACK with no payload
+		join(ethernetIPv4, ipv4(layers.ProtocolUDP, 0x2000, udp("fragment"))),
+	}
+	r, err := NewReader(decoder.New(classicFile(binary.LittleEndian, false, LinkTypeEthernet, frames...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloads, err := r.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 2 {
+		t.Fatalf("expected 2 payloads got %d", len(payloads))
+	}
+
+	pl := payloads[0]
+	if pl.Protocol != layers.ProtocolUDP || !pl.Src.Equal(net.IPv4(192, 168, 0, 1)) || !pl.Dst.Equal(net.IPv4(192, 168, 0, 2)) ||
+		pl.SrcPort != 12345 || pl.DstPort != 53 || pl.Timestamp.Unix() != 1600000000 {
+		t.Errorf("unexpected payload %+v", pl)
+	}
+	if s := pl.Packet.StringZeroPadded(5); s != "hello" || !pl.Packet.EOF() {
+		t.Errorf("expected hello got %q", s)
+	}
+
+	pl = payloads[1]
+	if pl.Protocol != layers.ProtocolTCP || pl.Src.String() != "2001:db8::1" || pl.DstPort != 80 || pl.Timestamp.Unix() != 1600000002 {
+		t.Errorf("unexpected payload %+v", pl)
+	}
+	if s := string(pl.Packet.PeekRemainingBytes()); s != "GET /" {
+		t.Errorf("expected GET / got %q", s)
+	}
+}
+
+func TestPayloadLinkTypes(t *testing.T) {
+	tests := []struct {
+		link  LinkType
+		frame []byte
+	}{
+		{LinkTypeRaw, ipv4(layers.ProtocolUDP, 0, udp("raw4"))},
+		{LinkTypeRaw, ipv6(layers.ProtocolUDP, udp("raw6"))},
+		{LinkTypeIPv4, ipv4(layers.ProtocolUDP, 0, udp("ipv4"))},
+		{LinkTypeNull, join([]byte{2, 0, 0, 0}, ipv4(layers.ProtocolUDP, 0, udp("null")))},
+		{LinkTypeNull, join([]byte{0, 0, 0, 30}, ipv6(layers.ProtocolUDP, udp("null6")))},
+	}
+	for _, test := range tests {
+		pl, err := Record{LinkType: test.link, Data: test.frame}.Payload()
+		if err != nil {
+			t.Errorf("%d: %v", test.link, err)
+			continue
+		}
+		if pl.Protocol != layers.ProtocolUDP || len(pl.Packet.PeekRemainingBytes()) < 4 {
+			t.Errorf("%d: unexpected payload %+v", test.link, pl)
+		}
+	}
+}
+
+func TestPayloadErrors(t *testing.T) {
+	full := join(ethernetIPv4, ipv4(layers.ProtocolUDP, 0, udp("hello")))
+	badIHL := append([]byte{}, full...)
+	badIHL[14] = 0x44
+	badTCP := join(ethernetIPv4, ipv4(layers.ProtocolTCP, 0, tcp("x")))
+	badTCP[14+20+12] = 0x40
+
+	tests := []struct {
+		name  string
+		frame []byte
+		err   error
+	}{
+		{"truncated", full[:len(full)-2], decoder.ErrReadPastEndData},
+		{"short ethernet", full[:10], decoder.ErrReadPastEndData},
+		{"bad IHL", badIHL, layers.ErrInvalidHeader},
+		{"bad data offset", badTCP, layers.ErrInvalidHeader},
+		{"ICMP", join(ethernetIPv4, ipv4(1, 0, []byte("ping"))), ErrNoPayload},
+	}
+	for _, test := range tests {
+		_, err := Record{LinkType: LinkTypeEthernet, Data: test.frame}.Payload()
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v got %v", test.name, test.err, err)
+		}
+	}
+
+	// An invalid frame is reported with its offset and reading continues after it
+	r, _ := NewReader(decoder.New(classicFile(binary.BigEndian, false, LinkTypeEthernet, badIHL, full)))
+	var oe *decoder.OffsetError
+	if _, err := r.NextPayload(); !errors.As(err, &oe) || oe.Offset != 24 || !errors.Is(err, layers.ErrInvalidHeader) {
+		t.Errorf("expected layers.ErrInvalidHeader at 24 got %v", err)
+	}
+	if pl, err := r.NextPayload(); err != nil || pl.SrcPort != 12345 {
+		t.Errorf("unexpected payload %+v err %v", pl, err)
+	}
+	if _, err := r.NextPayload(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
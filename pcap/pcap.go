@@ -0,0 +1,309 @@
+// Package pcap reads classic pcap and pcapng capture files using a *decoder.Packet, and strips the
+// Ethernet, IP, UDP & TCP headers from the captured frames so the payloads can be decoded.
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrUnknownFormat = errors.New("unknown capture file format")
+var ErrMalformed = errors.New("malformed capture file")
+var ErrUnknownInterface = errors.New("unknown interface")
+
+// LinkType is the type of frame captured
+type LinkType uint32
+
+// Link types
+const (
+	LinkTypeNull     LinkType = 0
+	LinkTypeEthernet LinkType = 1
+	LinkTypeRaw      LinkType = 101 // IPv4 or IPv6 with no link layer header
+	LinkTypeIPv4     LinkType = 228
+	LinkTypeIPv6     LinkType = 229
+)
+
+// File format magic numbers
+const (
+	magicMicroseconds = 0xa1b2c3d4
+	magicNanoseconds  = 0xa1b23c4d
+	blockSHB          = 0x0a0d0d0a
+	blockIDB          = 0x00000001
+	blockSPB          = 0x00000003
+	blockEPB          = 0x00000006
+	byteOrderMagic    = 0x1a2b3c4d
+)
+
+// Record is a captured frame
+type Record struct {
+	Timestamp      time.Time
+	LinkType       LinkType
+	Interface      int    // The pcapng interface the frame was captured on, 0 for classic pcap
+	OriginalLength int    // The length of the frame on the wire, Data may be shorter
+	Data           []byte // The captured frame, which aliases the packet's buffer
+	Offset         int    // The offset of the record or block in the file
+}
+
+// iface is a pcapng interface description
+type iface struct {
+	linkType    LinkType
+	snapLen     int
+	unitsPerSec uint64
+}
+
+// Reader reads the records of a capture file
+type Reader struct {
+	p        *decoder.Packet
+	order    binary.ByteOrder // The endian of the file or current pcapng section
+	ng       bool
+	linkType LinkType // Classic pcap link type
+	nanos    bool     // Classic pcap timestamps are in nanoseconds
+	ifaces   []iface  // pcapng interfaces of the current section
+	section  bool     // A pcapng section header has been read
+}
+
+// NewReader returns a Reader of the capture file from the packet's current position, detecting its format
+// and endian from the file header. The packet's own byte order is left as it is
+func NewReader(p *decoder.Packet) (*Reader, error) {
+	r := &Reader{p: p, order: binary.BigEndian}
+	offset := p.Index()
+	b, err := p.PeekN(4)
+	if err != nil {
+		return nil, &decoder.OffsetError{Offset: offset, Err: ErrUnknownFormat}
+	}
+	switch magic := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]); magic {
+	case blockSHB:
+		r.ng = true
+		return r, nil
+	case magicMicroseconds, magicNanoseconds:
+		r.nanos = magic == magicNanoseconds
+	case swap32(magicMicroseconds), swap32(magicNanoseconds):
+		r.order = binary.LittleEndian
+		r.nanos = magic == swap32(magicNanoseconds)
+	default:
+		return nil, &decoder.OffsetError{Offset: offset, Err: ErrUnknownFormat}
+	}
+
+	defer p.SetByteOrder(p.ByteOrder())
+	p.SetByteOrder(r.order)
+	// magic, version major & minor, thiszone, sigfigs, snaplen, network
+	p.Bytes(20)
+	r.linkType = LinkType(p.Uint32())
+	if p.Err != nil {
+		p.Err = nil
+		p.SeekTo(offset)
+		return nil, &decoder.OffsetError{Offset: offset, Err: decoder.ErrReadPastEndData}
+	}
+	return r, nil
+}
+
+func swap32(v uint32) uint32 {
+	return v>>24 | v>>8&0xff00 | v<<8&0xff0000 | v<<24
+}
+
+// Next returns the next captured frame, or io.EOF at the end of the file.
+// Errors are an *decoder.OffsetError with the offset of the record or block
+func (r *Reader) Next() (Record, error) {
+	defer r.p.SetByteOrder(r.p.ByteOrder())
+	r.p.SetByteOrder(r.order)
+	if r.ng {
+		return r.nextBlock()
+	}
+	p := r.p
+	if p.RemainingLength() == 0 {
+		return Record{}, io.EOF
+	}
+	rec := Record{Offset: p.Index(), LinkType: r.linkType}
+	sec := p.Uint32()
+	frac := p.Uint32()
+	length := int(p.Uint32())
+	rec.OriginalLength = int(p.Uint32())
+	if p.Err != nil {
+		return Record{}, r.fail(rec.Offset, decoder.ErrReadPastEndData)
+	}
+	if length > p.RemainingLength() {
+		return Record{}, r.fail(rec.Offset, decoder.ErrReadPastEndData)
+	}
+	rec.Data = p.Bytes(length)
+	if r.nanos {
+		rec.Timestamp = time.Unix(int64(sec), int64(frac)).UTC()
+	} else {
+		rec.Timestamp = time.Unix(int64(sec), int64(frac)*1000).UTC()
+	}
+	return rec, nil
+}
+
+// fail restores the reader to the given offset and returns the error there
+func (r *Reader) fail(offset int, err error) error {
+	r.p.Err = nil
+	r.p.SeekTo(offset)
+	return &decoder.OffsetError{Offset: offset, Err: err}
+}
+
+// nextBlock reads pcapng blocks until a packet block is found
+func (r *Reader) nextBlock() (Record, error) {
+	p := r.p
+	for {
+		if p.RemainingLength() == 0 {
+			return Record{}, io.EOF
+		}
+		offset := p.Index()
+		if p.RemainingLength() < 12 {
+			return Record{}, r.fail(offset, decoder.ErrReadPastEndData)
+		}
+
+		typ := p.Uint32At(offset)
+		if typ == blockSHB {
+			// The byte order magic sets the endian of the section, including the block length
+			switch p.ByteAt(offset + 8) {
+			case byteOrderMagic >> 24:
+				r.order = binary.BigEndian
+			case byteOrderMagic & 0xff:
+				r.order = binary.LittleEndian
+			}
+			p.SetByteOrder(r.order)
+			if p.Uint32At(offset+8) != byteOrderMagic {
+				return Record{}, r.fail(offset, ErrMalformed)
+			}
+			r.section = true
+			r.ifaces = r.ifaces[:0]
+		} else if !r.section {
+			return Record{}, r.fail(offset, ErrMalformed)
+		}
+
+		length := int(p.Uint32At(offset + 4))
+		if length < 12 || length%4 != 0 {
+			return Record{}, r.fail(offset, ErrMalformed)
+		}
+		if length > p.RemainingLength() {
+			return Record{}, r.fail(offset, decoder.ErrReadPastEndData)
+		}
+		if int(p.Uint32At(offset+length-4)) != length {
+			return Record{}, r.fail(offset, ErrMalformed)
+		}
+		p.Bytes(8)
+		body := p.Sub(length - 12)
+		p.Bytes(4)
+
+		switch typ {
+		case blockIDB:
+			if err := r.readInterface(body); err != nil {
+				return Record{}, r.fail(offset, err)
+			}
+		case blockEPB:
+			rec, err := r.readEnhanced(body)
+			if err != nil {
+				return Record{}, r.fail(offset, err)
+			}
+			rec.Offset = offset
+			return rec, nil
+		case blockSPB:
+			rec, err := r.readSimple(body)
+			if err != nil {
+				return Record{}, r.fail(offset, err)
+			}
+			rec.Offset = offset
+			return rec, nil
+		}
+	}
+}
+
+// Interface description block option codes
+const (
+	optEndOfOpt = 0
+	optTSResol  = 9
+)
+
+func (r *Reader) readInterface(body *decoder.Packet) error {
+	ifc := iface{unitsPerSec: 1000000}
+	ifc.linkType = LinkType(body.Uint16())
+	body.Uint16() // reserved
+	ifc.snapLen = int(body.Uint32())
+	if body.Err != nil {
+		return ErrMalformed
+	}
+	for body.RemainingLength() >= 4 {
+		code := body.Uint16()
+		length := int(body.Uint16())
+		v := body.Bytes(length)
+		body.Bytes((4 - length%4) % 4)
+		if body.Err != nil {
+			return ErrMalformed
+		}
+		if code == optEndOfOpt {
+			break
+		}
+		if code == optTSResol && length == 1 {
+			// The MSB selects a negative power of 2, otherwise of 10
+			n := uint(v[0] & 0x7f)
+			if v[0]&0x80 != 0 {
+				if n > 63 {
+					return ErrMalformed
+				}
+				ifc.unitsPerSec = 1 << n
+			} else {
+				if n > 19 {
+					return ErrMalformed
+				}
+				ifc.unitsPerSec = 1
+				for i := uint(0); i < n; i++ {
+					ifc.unitsPerSec *= 10
+				}
+			}
+		}
+	}
+	r.ifaces = append(r.ifaces, ifc)
+	return nil
+}
+
+func (r *Reader) readEnhanced(body *decoder.Packet) (Record, error) {
+	id := int(body.Uint32())
+	ts := uint64(body.Uint32())<<32 | uint64(body.Uint32())
+	length := int(body.Uint32())
+	rec := Record{Interface: id, OriginalLength: int(body.Uint32())}
+	rec.Data = body.Bytes(length)
+	if body.Err != nil {
+		return Record{}, ErrMalformed
+	}
+	if id >= len(r.ifaces) {
+		return Record{}, ErrUnknownInterface
+	}
+	ifc := r.ifaces[id]
+	rec.LinkType = ifc.linkType
+	rec.Timestamp = timestamp(ts, ifc.unitsPerSec)
+	return rec, nil
+}
+
+func (r *Reader) readSimple(body *decoder.Packet) (Record, error) {
+	if len(r.ifaces) == 0 {
+		return Record{}, ErrUnknownInterface
+	}
+	ifc := r.ifaces[0]
+	rec := Record{OriginalLength: int(body.Uint32()), LinkType: ifc.linkType}
+	// The captured length is the smallest of the original length, snap length & block
+	length := rec.OriginalLength
+	if ifc.snapLen > 0 && ifc.snapLen < length {
+		length = ifc.snapLen
+	}
+	if body.RemainingLength() < length {
+		length = body.RemainingLength()
+	}
+	rec.Data = body.Bytes(length)
+	if body.Err != nil {
+		return Record{}, ErrMalformed
+	}
+	return rec, nil
+}
+
+// timestamp converts a count of units since the epoch to a time
+func timestamp(ts uint64, unitsPerSec uint64) time.Time {
+	sec := ts / unitsPerSec
+	hi, lo := bits.Mul64(ts%unitsPerSec, 1e9)
+	nsec, _ := bits.Div64(hi, lo, unitsPerSec)
+	return time.Unix(int64(sec), int64(nsec)).UTC()
+}
@@ -0,0 +1,234 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+// classicFile returns a classic pcap file of the given frames, one second apart
+func classicFile(order binary.ByteOrder, nanos bool, link LinkType, frames ...[]byte) []byte {
+	b := make([]byte, 24)
+	if nanos {
+		order.PutUint32(b, magicNanoseconds)
+	} else {
+		order.PutUint32(b, magicMicroseconds)
+	}
+	order.PutUint16(b[4:], 2)
+	order.PutUint16(b[6:], 4)
+	order.PutUint32(b[16:], 65535)
+	order.PutUint32(b[20:], uint32(link))
+	for i, f := range frames {
+		h := make([]byte, 16)
+		order.PutUint32(h, uint32(1600000000+i))
+		order.PutUint32(h[4:], 500)
+		order.PutUint32(h[8:], uint32(len(f)))
+		order.PutUint32(h[12:], uint32(len(f)))
+		b = append(b, h...)
+		b = append(b, f...)
+	}
+	return b
+}
+
+// block returns a pcapng block of the given type & body, padding the body to 32 bits
+func block(order binary.ByteOrder, typ uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b := make([]byte, 8, len(body)+12)
+	order.PutUint32(b, typ)
+	order.PutUint32(b[4:], uint32(len(body)+12))
+	b = append(b, body...)
+	b = append(b, b[4:8]...)
+	return b
+}
+
+func sectionHeader(order binary.ByteOrder) []byte {
+	body := make([]byte, 16)
+	order.PutUint32(body, byteOrderMagic)
+	order.PutUint16(body[4:], 1)
+	binary.BigEndian.PutUint64(body[8:], 0xffffffffffffffff)
+	return block(order, blockSHB, body)
+}
+
+func interfaceDescription(order binary.ByteOrder, link LinkType, snapLen int, tsresol byte) []byte {
+	body := make([]byte, 8)
+	order.PutUint16(body, uint16(link))
+	order.PutUint32(body[4:], uint32(snapLen))
+	if tsresol != 0 {
+		opt := make([]byte, 12)
+		order.PutUint16(opt, optTSResol)
+		order.PutUint16(opt[2:], 1)
+		opt[4] = tsresol
+		body = append(body, opt...) // Followed by opt_endofopt
+	}
+	return block(order, blockIDB, body)
+}
+
+func enhancedPacket(order binary.ByteOrder, id int, ts uint64, frame []byte) []byte {
+	body := make([]byte, 20)
+	order.PutUint32(body, uint32(id))
+	order.PutUint32(body[4:], uint32(ts>>32))
+	order.PutUint32(body[8:], uint32(ts))
+	order.PutUint32(body[12:], uint32(len(frame)))
+	order.PutUint32(body[16:], uint32(len(frame)))
+	return block(order, blockEPB, append(body, frame...))
+}
+
+func TestClassic(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, nanos := range []bool{false, true} {
+			file := classicFile(order, nanos, LinkTypeEthernet, []byte("frame one"), []byte("two"))
+			r, err := NewReader(decoder.New(file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec, err := r.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := time.Unix(1600000000, 500000).UTC()
+			if nanos {
+				want = time.Unix(1600000000, 500).UTC()
+			}
+			if string(rec.Data) != "frame one" || rec.LinkType != LinkTypeEthernet || !rec.Timestamp.Equal(want) || rec.Offset != 24 {
+				t.Errorf("%v %v: unexpected record %+v", order, nanos, rec)
+			}
+			if rec, err = r.Next(); err != nil || string(rec.Data) != "two" || rec.OriginalLength != 3 {
+				t.Errorf("%v %v: unexpected record %+v err %v", order, nanos, rec, err)
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("expected io.EOF got %v", err)
+			}
+		}
+	}
+}
+
+func TestClassicErrors(t *testing.T) {
+	if _, err := NewReader(decoder.New([]byte("not a capture file"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat got %v", err)
+	}
+	file := classicFile(binary.LittleEndian, false, LinkTypeEthernet, []byte("frame"))
+	if _, err := NewReader(decoder.New(file[:20])); !errors.Is(err, decoder.ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+	p := decoder.New(file[:len(file)-1])
+	r, _ := NewReader(p)
+	var oe *decoder.OffsetError
+	if _, err := r.Next(); !errors.Is(err, decoder.ErrReadPastEndData) || !errors.As(err, &oe) || oe.Offset != 24 {
+		t.Errorf("expected ErrReadPastEndData at 24 got %v", err)
+	}
+	if p.Index() != 24 {
+		t.Errorf("expected packet restored to 24 got %d", p.Index())
+	}
+}
+
+func TestPcapng(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		var file []byte
+		file = append(file, sectionHeader(order)...)
+		file = append(file, interfaceDescription(order, LinkTypeEthernet, 4, 0)...)
+		file = append(file, interfaceDescription(order, LinkTypeRaw, 0, 9)...)
+		file = append(file, block(order, 0x0bad, []byte("custom"))...)
+		file = append(file, enhancedPacket(order, 1, 1600000000123456789, []byte("abcde"))...)
+		file = append(file, enhancedPacket(order, 0, 1600000000123456, []byte("xyz"))...)
+		spb := make([]byte, 4)
+		order.PutUint32(spb, 6)
+		file = append(file, block(order, blockSPB, append(spb, "simple"...))...)
+
+		r, err := NewReader(decoder.New(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(rec.Data) != "abcde" || rec.Interface != 1 || rec.LinkType != LinkTypeRaw ||
+			!rec.Timestamp.Equal(time.Unix(1600000000, 123456789)) {
+			t.Errorf("%v: unexpected record %+v", order, rec)
+		}
+		rec, err = r.Next()
+		if err != nil || string(rec.Data) != "xyz" || rec.LinkType != LinkTypeEthernet ||
+			!rec.Timestamp.Equal(time.Unix(1600000000, 123456000)) {
+			t.Errorf("%v: unexpected record %+v err %v", order, rec, err)
+		}
+		// The simple packet is cut to the interface's snap length
+		rec, err = r.Next()
+		if err != nil || string(rec.Data) != "simp" || rec.OriginalLength != 6 {
+			t.Errorf("%v: unexpected record %+v err %v", order, rec, err)
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("expected io.EOF got %v", err)
+		}
+	}
+}
+
+func TestReaderKeepsByteOrder(t *testing.T) {
+	files := [][]byte{
+		classicFile(binary.LittleEndian, false, LinkTypeRaw, []byte("abc")),
+		append(append(sectionHeader(binary.LittleEndian), interfaceDescription(binary.LittleEndian, LinkTypeRaw, 0, 0)...),
+			enhancedPacket(binary.LittleEndian, 0, 1, []byte("abc"))...),
+	}
+	for i, file := range files {
+		p := decoder.New(file)
+		r, err := NewReader(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ByteOrder() != binary.BigEndian {
+			t.Errorf("%d: expected NewReader to leave the packet big endian", i)
+		}
+		if rec, err := r.Next(); err != nil || string(rec.Data) != "abc" {
+			t.Errorf("%d: unexpected record %+v err %v", i, rec, err)
+		}
+		if p.ByteOrder() != binary.BigEndian {
+			t.Errorf("%d: expected Next to leave the packet big endian", i)
+		}
+	}
+}
+
+func TestPcapngErrors(t *testing.T) {
+	order := binary.LittleEndian
+	shb := sectionHeader(order)
+	tests := []struct {
+		name string
+		file []byte
+		err  error
+	}{
+		{"unknown interface", append(shb, enhancedPacket(order, 0, 0, []byte("x"))...), ErrUnknownInterface},
+		{"bad trailing length", append(append([]byte{}, shb[:len(shb)-4]...), 0, 0, 0, 0), ErrMalformed},
+		{"bad byte order", append(append([]byte{}, shb[:8]...), 0, 0, 0, 0, 0, 0, 0, 0, 28, 0, 0, 0), ErrMalformed},
+		{"truncated", shb[:len(shb)-4], decoder.ErrReadPastEndData},
+	}
+	for _, test := range tests {
+		r, err := NewReader(decoder.New(test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Next(); !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		ts    uint64
+		units uint64
+		want  time.Time
+	}{
+		{1500000000, 1, time.Unix(1500000000, 0)},
+		{1500000000*1024 + 512, 1024, time.Unix(1500000000, 500000000)},
+		{1500000000000000000 + 1000, 1000000000, time.Unix(1500000000, 1000)},
+	}
+	for _, test := range tests {
+		if v := timestamp(test.ts, test.units); !v.Equal(test.want) {
+			t.Errorf("%d/%d: expected %v got %v", test.ts, test.units, test.want, v)
+		}
+	}
+}
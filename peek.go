@@ -0,0 +1,104 @@
+package decoder
+
+// The Peek functions read a value at the internal pointer without moving it or changing Err,
+// returning any error instead
+
+// PeekAt returns a copy of the packet with its internal pointer at the given absolute offset, reads from it
+// do not affect this packet. If the offset is outside of the data the copy's Err is set to ErrReadPastEndData
+func (p *Packet) PeekAt(offset int) *Packet {
+	q := p.cursorAt(offset)
+	return &q
+}
+
+// cursorAt returns a copy of the packet with its internal pointer at the given absolute offset
+func (p *Packet) cursorAt(offset int) Packet {
+	q := *p
+	q.Err = nil
+	if offset < 0 || offset > p.length {
+		q.setIndex(p.length)
+		q.Err = &OffsetError{Offset: offset, Err: ErrReadPastEndData}
+		return q
+	}
+	q.setIndex(offset)
+	return q
+}
+
+// PeekN returns the given number of bytes at the internal pointer, the slice aliases the packet's buffer
+func (p *Packet) PeekN(length int) ([]byte, error) {
+	q := *p
+	q.Err = nil
+	v := q.Bytes(length)
+	return v, q.Err
+}
+
+// PeekByte returns the byte at the internal pointer
+func (p *Packet) PeekByte() (byte, error) {
+	q := *p
+	q.Err = nil
+	v := q.Byte()
+	return v, q.Err
+}
+
+// PeekUint16 returns the uint16 at the internal pointer
+func (p *Packet) PeekUint16() (uint16, error) {
+	q := *p
+	q.Err = nil
+	v := q.Uint16()
+	return v, q.Err
+}
+
+// PeekUint24 returns the 24 bit value at the internal pointer
+func (p *Packet) PeekUint24() (uint32, error) {
+	q := *p
+	q.Err = nil
+	v := q.Uint24()
+	return v, q.Err
+}
+
+// PeekUint32 returns the uint32 at the internal pointer
+func (p *Packet) PeekUint32() (uint32, error) {
+	q := *p
+	q.Err = nil
+	v := q.Uint32()
+	return v, q.Err
+}
+
+// PeekUint64 returns the uint64 at the internal pointer
+func (p *Packet) PeekUint64() (uint64, error) {
+	q := *p
+	q.Err = nil
+	v := q.Uint64()
+	return v, q.Err
+}
+
+// PeekFloat32 returns the float32 at the internal pointer
+func (p *Packet) PeekFloat32() (float32, error) {
+	q := *p
+	q.Err = nil
+	v := q.Float32()
+	return v, q.Err
+}
+
+// PeekFloat64 returns the float64 at the internal pointer
+func (p *Packet) PeekFloat64() (float64, error) {
+	q := *p
+	q.Err = nil
+	v := q.Float64()
+	return v, q.Err
+}
+
+// PeekCString returns the NULL terminated string at the internal pointer
+func (p *Packet) PeekCString() (string, error) {
+	q := *p
+	q.Err = nil
+	v := q.CString()
+	return v, q.Err
+}
+
+// PeekLine returns the line at the internal pointer
+func (p *Packet) PeekLine() (string, error) {
+	q := *p
+	q.Err = nil
+	v := q.Line()
+	return v, q.Err
+}
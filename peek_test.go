@@ -0,0 +1,80 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPeek(t *testing.T) {
+	p := New([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x41, 0x00})
+	p.Err = ErrReadNoData // Peeks must leave Err as is
+
+	check := func(name string, v, expect interface{}, err error) {
+		if err != nil {
+			t.Errorf("%s: got unexpected err: %s", name, err)
+		}
+		if v != expect {
+			t.Errorf("%s: expected %v got %v", name, expect, v)
+		}
+		if p.Index() != 0 || p.Err != ErrReadNoData {
+			t.Errorf("%s: expected index & err to be unchanged got %d & %v", name, p.Index(), p.Err)
+		}
+	}
+
+	v8, err := p.PeekByte()
+	check("PeekByte", v8, byte(0x01), err)
+	v16, err := p.PeekUint16()
+	check("PeekUint16", v16, uint16(0x0102), err)
+	v24, err := p.PeekUint24()
+	check("PeekUint24", v24, uint32(0x010203), err)
+	v32, err := p.PeekUint32()
+	check("PeekUint32", v32, uint32(0x01020304), err)
+	v64, err := p.PeekUint64()
+	check("PeekUint64", v64, uint64(0x0102030405060708), err)
+
+	p.Err = nil
+	p.Bytes(8)
+	p.Err = ErrReadNoData
+	s, err := p.PeekCString()
+	if s != "A" || err != nil || p.Index() != 8 {
+		t.Errorf("expected 'A' at 8 got '%s' at %d err %v", s, p.Index(), err)
+	}
+
+	p.Bytes(2)
+	if _, err := p.PeekUint16(); err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+	if p.Err != ErrReadNoData {
+		t.Errorf("expected err to be unchanged got %v", p.Err)
+	}
+}
+
+func TestPeekAt(t *testing.T) {
+	p := New([]byte{0x01, 0x02, 0x03, 0x04})
+	q := p.PeekAt(2)
+	if v := q.Uint16(); v != 0x0304 || q.Err != nil {
+		t.Errorf("expected 0x0304 got %X err %v", v, q.Err)
+	}
+	if p.Index() != 0 {
+		t.Errorf("expected index 0 got %d", p.Index())
+	}
+
+	q = p.PeekAt(5)
+	if !errors.Is(q.Err, ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", q.Err)
+	}
+	if !p.PeekAt(4).EOF() {
+		t.Error("expected EOF")
+	}
+}
+
+func TestPeekDoesNotAllocate(t *testing.T) {
+	p := New([]byte{0x01, 0x02, 0x03, 0x04})
+	allocs := testing.AllocsPerRun(100, func() {
+		p.PeekUint16()
+		p.PeekUint32()
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations got %.0f", allocs)
+	}
+}
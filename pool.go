@@ -0,0 +1,24 @@
+package decoder
+
+import "sync"
+
+var packetPool = sync.Pool{
+	New: func() interface{} {
+		return new(Packet)
+	},
+}
+
+// Acquire returns a packet from a shared pool loaded with the given data, it should be
+// returned with Release once finished with
+func Acquire(b []byte) *Packet {
+	p := packetPool.Get().(*Packet)
+	p.Load(b)
+	return p
+}
+
+// Release returns the packet to the shared pool, the packet must not be used afterwards.
+// All settings are cleared so the next Acquire gets a packet as if from New
+func Release(p *Packet) {
+	*p = Packet{}
+	packetPool.Put(p)
+}
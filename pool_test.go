@@ -0,0 +1,97 @@
+package decoder
+
+import "testing"
+
+var numericPacket = []byte{
+	0x02,       // STX
+	0xde, 0xad, // Uint16
+	0x01, 0x02, 0x03, // Uint24
+	0x01, 0x02, 0x03, 0x04, // Uint32
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, // Uint64
+	0x3f, 0x80, 0x00, 0x00, // Float32
+	0x03, // ETX
+}
+
+func decodeNumeric(p *Packet) {
+	p.Byte()
+	p.Uint16()
+	p.Uint24()
+	p.Uint32()
+	p.Uint64()
+	p.Float32()
+	p.Byte()
+}
+
+func TestLoad(t *testing.T) {
+	p := New([]byte{0x00, 0xff})
+	p.SeekByte(0xff)
+	p.SetLittleEndian()
+	p.Uint16()
+	if p.Err == nil {
+		t.Error("expected error reading past end of data")
+	}
+
+	p.Load(numericPacket)
+	if p.Index() != 0 || p.Err != nil {
+		t.Errorf("expected index 0 & no error got %d & %v", p.Index(), p.Err)
+	}
+	if p.Skipped() != 0 {
+		t.Errorf("expected Skipped to be reset got %d", p.Skipped())
+	}
+	p.Byte()
+	if v := p.Uint16(); v != 0xdead {
+		t.Errorf("expected big endian 0xdead got %X", v)
+	}
+}
+
+func TestAcquireRelease(t *testing.T) {
+	p := Acquire(numericPacket)
+	p.SetEncoding(EncodingLatin1)
+	decodeNumeric(p)
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+	if !p.EOF() {
+		t.Error("expected EOF")
+	}
+	Release(p)
+	if p.buf != nil || p.encoding != EncodingUTF8 {
+		t.Error("expected released packet to be cleared")
+	}
+}
+
+func TestLoadDoesNotAllocate(t *testing.T) {
+	p := New(nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Load(numericPacket)
+		decodeNumeric(p)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations got %.0f", allocs)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		decodeNumeric(New(numericPacket))
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	b.ReportAllocs()
+	p := New(nil)
+	for i := 0; i < b.N; i++ {
+		p.Load(numericPacket)
+		decodeNumeric(p)
+	}
+}
+
+func BenchmarkAcquireRelease(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := Acquire(numericPacket)
+		decodeNumeric(p)
+		Release(p)
+	}
+}
@@ -0,0 +1,92 @@
+package decoder
+
+// LenKind describes how the length of a length prefixed value is encoded
+type LenKind int
+
+// Length prefix kinds, 16, 24 & 32 bit lengths use the packet's endian
+const (
+	LenUint8   LenKind = iota // Single byte length
+	LenUint16                 // 2 byte length
+	LenUint24                 // 3 byte length
+	LenUint32                 // 4 byte length
+	LenUvarint                // Unsigned LEB128 varint length
+)
+
+// LenIncludesPrefix can be or'd with a LenKind when the length includes the prefix itself,
+// e.g. LenUint16|LenIncludesPrefix
+const LenIncludesPrefix LenKind = 0x100
+
+// SetMaxLength sets the largest length allowed by length prefixed reads, 0 means no limit
+func (p *Packet) SetMaxLength(n int) {
+	p.maxLength = n
+}
+
+// PrefixedBytes returns the bytes at the internal pointer using the given length prefix kind
+// and increments it accordingly. The returned slice aliases the packet's buffer
+func (p *Packet) PrefixedBytes(kind LenKind) []byte {
+	idx := p.idx
+	l := p.prefixLength(kind)
+	if p.Err != nil {
+		p.setIndex(idx)
+		return nil
+	}
+	if p.idx+l > p.length {
+		p.setIndex(idx)
+		p.Err = ErrReadPastEndData
+		return nil
+	}
+	b := p.buf[p.idx : p.idx+l]
+	p.idx += l
+	return b
+}
+
+// PrefixedString returns the string at the internal pointer using the given length prefix kind
+// and increments it accordingly
+func (p *Packet) PrefixedString(kind LenKind) string {
+	b := p.PrefixedBytes(kind)
+	if p.Err != nil {
+		return ""
+	}
+	return p.text(b)
+}
+
+// prefixLength reads the length prefix returning the length of the data that follows it
+func (p *Packet) prefixLength(kind LenKind) int {
+	idx := p.idx
+	var l uint64
+	switch kind &^ LenIncludesPrefix {
+	case LenUint8:
+		l = uint64(p.Byte())
+	case LenUint16:
+		l = uint64(p.Uint16())
+	case LenUint24:
+		l = uint64(p.Uint24())
+	case LenUint32:
+		l = uint64(p.Uint32())
+	case LenUvarint:
+		l = p.Uvarint()
+	default:
+		p.Err = ErrReadInvalidLength
+		return 0
+	}
+	if p.Err != nil {
+		return 0
+	}
+	if kind&LenIncludesPrefix != 0 {
+		prefix := uint64(p.idx - idx)
+		if l < prefix {
+			p.Err = ErrReadInvalidLength
+			return 0
+		}
+		l -= prefix
+	}
+	if p.maxLength > 0 && l > uint64(p.maxLength) {
+		p.Err = ErrReadInvalidLength
+		return 0
+	}
+	if l > uint64(p.length) {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	return int(l)
+}
@@ -0,0 +1,91 @@
+package decoder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrefixedBytes(t *testing.T) {
+	tests := []struct {
+		kind   LenKind
+		little bool
+		input  []byte
+		expect []byte
+		err    error
+	}{
+		{LenUint8, false, []byte{0x02, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint16, false, []byte{0x00, 0x02, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint16, true, []byte{0x02, 0x00, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint24, false, []byte{0x00, 0x00, 0x02, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint32, false, []byte{0x00, 0x00, 0x00, 0x02, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUvarint, false, []byte{0x02, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint8 | LenIncludesPrefix, false, []byte{0x03, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint16 | LenIncludesPrefix, false, []byte{0x00, 0x04, 0x41, 0x42, 0xff}, []byte("AB"), nil},
+		{LenUint8, false, []byte{0x00, 0xff}, []byte{}, nil},
+		{LenUint8 | LenIncludesPrefix, false, []byte{0x00, 0xff}, nil, ErrReadInvalidLength},
+		{LenUint8, false, []byte{0x05, 0x41, 0x42}, nil, ErrReadPastEndData},
+		{LenUint32, false, []byte{0xff, 0xff, 0xff, 0xff, 0x41}, nil, ErrReadPastEndData},
+		{LenUvarint, false, []byte{0x80}, nil, ErrReadPastEndData},
+		{LenUint16, false, []byte{0x00}, nil, ErrReadPastEndData},
+	}
+
+	for _, test := range tests {
+		p := New(test.input)
+		if test.little {
+			p.SetLittleEndian()
+		}
+		b := p.PrefixedBytes(test.kind)
+		if p.Err != test.err {
+			t.Errorf("with % X expected err %v got %v", test.input, test.err, p.Err)
+		}
+		if !bytes.Equal(b, test.expect) {
+			t.Errorf("with % X expected % X got % X", test.input, test.expect, b)
+		}
+		if test.err == nil {
+			if nb := p.Byte(); nb != 0xff {
+				t.Errorf("with % X expected next byte 0xff got %X", test.input, nb)
+			}
+		} else if p.Index() != 0 {
+			t.Errorf("with % X expected index to be restored got %d", test.input, p.Index())
+		}
+	}
+}
+
+func TestPrefixedMaxLength(t *testing.T) {
+	p := New([]byte{0x00, 0x00, 0x00, 0x03, 0x41, 0x42, 0x43})
+	p.SetMaxLength(2)
+	s := p.PrefixedString(LenUint32)
+	if p.Err != ErrReadInvalidLength {
+		t.Errorf("expected ErrReadInvalidLength got %v", p.Err)
+	}
+	if s != "" {
+		t.Errorf("expected '' got '%s'", s)
+	}
+
+	p.Reset()
+	p.SetMaxLength(3)
+	s = p.PrefixedString(LenUint32)
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+	if s != "ABC" {
+		t.Errorf("expected 'ABC' got '%s'", s)
+	}
+}
+
+func TestVarint(t *testing.T) {
+	p := New([]byte{0xac, 0x02, 0x03, 0x80})
+	if v := p.Uvarint(); v != 300 {
+		t.Errorf("expected 300 got %d", v)
+	}
+	if v := p.Varint(); v != -2 {
+		t.Errorf("expected -2 got %d", v)
+	}
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+	p.Uvarint()
+	if p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", p.Err)
+	}
+}
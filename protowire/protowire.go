@@ -0,0 +1,252 @@
+// Package protowire reads the Protocol Buffers wire format using a *decoder.Packet, without needing
+// the .proto files or generated code.
+package protowire
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+var ErrInvalidFieldNumber = errors.New("invalid field number")
+var ErrInvalidWireType = errors.New("invalid wire type")
+var ErrUnexpectedEndGroup = errors.New("unexpected end group")
+var ErrMaxDepth = errors.New("maximum group nesting depth exceeded")
+
+// DefaultMaxDepth is the deepest group nesting allowed unless changed
+const DefaultMaxDepth = 64
+
+// MaxFieldNumber is the largest valid field number
+const MaxFieldNumber = 1<<29 - 1
+
+// WireType is how a field's value is encoded
+type WireType uint8
+
+// Wire types
+const (
+	VarintType     WireType = 0
+	Fixed64Type    WireType = 1
+	BytesType      WireType = 2
+	StartGroupType WireType = 3
+	EndGroupType   WireType = 4
+	Fixed32Type    WireType = 5
+)
+
+var wireTypeNames = [...]string{"varint", "fixed64", "bytes", "start group", "end group", "fixed32"}
+
+func (t WireType) String() string {
+	if int(t) < len(wireTypeNames) {
+		return wireTypeNames[t]
+	}
+	return "unknown"
+}
+
+// Field is a single field read from a message. Only the value matching the wire type is set
+type Field struct {
+	Number  int32
+	Type    WireType
+	Offset  int    // The offset of the field's tag
+	Varint  uint64 // VarintType value
+	Fixed32 uint32 // Fixed32Type value
+	Fixed64 uint64 // Fixed64Type value
+	Bytes   []byte // BytesType value, or the encoded fields within a group
+	Raw     []byte // The whole encoded field including its tag, for dumping fields that aren't understood
+}
+
+// Reader reads the fields of a message
+type Reader struct {
+	p        *decoder.Packet
+	MaxDepth int // The deepest group nesting allowed
+}
+
+// NewReader returns a Reader of the fields from the packet's current position to its end, the packet's
+// byte order is left as it is
+func NewReader(p *decoder.Packet) *Reader {
+	return &Reader{
+		p:        p,
+		MaxDepth: DefaultMaxDepth,
+	}
+}
+
+// Next returns the next field, or io.EOF at the end of the message.
+// Errors are an *decoder.OffsetError with the offset of the field
+func (r *Reader) Next() (Field, error) {
+	if r.p.RemainingLength() == 0 {
+		return Field{}, io.EOF
+	}
+	defer r.p.SetByteOrder(r.p.ByteOrder())
+	r.p.SetLittleEndian()
+	f, err := r.field(0)
+	if err == nil && f.Type == EndGroupType {
+		r.p.SeekTo(f.Offset)
+		return Field{}, &decoder.OffsetError{Offset: f.Offset, Err: ErrUnexpectedEndGroup}
+	}
+	return f, err
+}
+
+// All returns all the remaining fields
+func (r *Reader) All() ([]Field, error) {
+	var v []Field
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return v, err
+		}
+		v = append(v, f)
+	}
+}
+
+func (r *Reader) field(depth int) (Field, error) {
+	p := r.p
+	f := Field{Offset: p.Index()}
+	fail := func(err error) (Field, error) {
+		p.Err = nil
+		p.SeekTo(f.Offset)
+		return Field{}, &decoder.OffsetError{Offset: f.Offset, Err: err}
+	}
+
+	tag := p.Uvarint()
+	if p.Err != nil {
+		return fail(p.Err)
+	}
+	f.Type = WireType(tag & 0x07)
+	if tag>>3 == 0 || tag>>3 > MaxFieldNumber {
+		return fail(ErrInvalidFieldNumber)
+	}
+	f.Number = int32(tag >> 3)
+
+	switch f.Type {
+	case VarintType:
+		f.Varint = p.Uvarint()
+	case Fixed64Type:
+		f.Fixed64 = p.Uint64()
+	case BytesType:
+		f.Bytes = p.PrefixedBytes(decoder.LenUvarint)
+	case Fixed32Type:
+		f.Fixed32 = p.Uint32()
+	case EndGroupType:
+	case StartGroupType:
+		if depth >= r.MaxDepth {
+			return fail(ErrMaxDepth)
+		}
+		start := p.Index()
+		for {
+			end := p.Index()
+			if p.RemainingLength() == 0 {
+				return fail(decoder.ErrReadPastEndData)
+			}
+			g, err := r.field(depth + 1)
+			if err != nil {
+				p.SeekTo(f.Offset)
+				return Field{}, err
+			}
+			if g.Type == EndGroupType {
+				if g.Number != f.Number {
+					return fail(ErrUnexpectedEndGroup)
+				}
+				f.Bytes = p.PeekBytes()[start:end]
+				break
+			}
+		}
+	default:
+		return fail(ErrInvalidWireType)
+	}
+	if p.Err != nil {
+		return fail(p.Err)
+	}
+	f.Raw = p.PeekBytes()[f.Offset:p.Index()]
+	return f, nil
+}
+
+// Int32 returns a varint as an int32, negative int32 values are sign extended to 64 bits on the wire
+func (f Field) Int32() int32 {
+	return int32(f.Varint)
+}
+
+// Int64 returns a varint as an int64
+func (f Field) Int64() int64 {
+	return int64(f.Varint)
+}
+
+// Sint64 returns a zig-zag encoded varint, as used by sint32 & sint64
+func (f Field) Sint64() int64 {
+	return DecodeZigZag(f.Varint)
+}
+
+// Bool returns a varint as a bool
+func (f Field) Bool() bool {
+	return f.Varint != 0
+}
+
+// Float returns a fixed32 as a float32
+func (f Field) Float() float32 {
+	return math.Float32frombits(f.Fixed32)
+}
+
+// Double returns a fixed64 as a float64
+func (f Field) Double() float64 {
+	return math.Float64frombits(f.Fixed64)
+}
+
+// StringValue returns length delimited bytes as a string
+func (f Field) StringValue() string {
+	return string(f.Bytes)
+}
+
+// Message returns a Reader of the fields of an embedded message or group
+func (f Field) Message() *Reader {
+	return NewReader(decoder.New(f.Bytes))
+}
+
+// PackedVarints returns the values of a packed repeated varint field
+func (f Field) PackedVarints() ([]uint64, error) {
+	p := decoder.New(f.Bytes)
+	var v []uint64
+	for p.RemainingLength() > 0 {
+		idx := p.Index()
+		x := p.Uvarint()
+		if p.Err != nil {
+			return v, &decoder.OffsetError{Offset: idx, Err: p.Err}
+		}
+		v = append(v, x)
+	}
+	return v, nil
+}
+
+// PackedFixed32 returns the values of a packed repeated fixed32, sfixed32 or float field
+func (f Field) PackedFixed32() ([]uint32, error) {
+	if len(f.Bytes)%4 != 0 {
+		return nil, &decoder.OffsetError{Offset: len(f.Bytes) / 4 * 4, Err: decoder.ErrReadPastEndData}
+	}
+	p := decoder.New(f.Bytes)
+	p.SetLittleEndian()
+	v := make([]uint32, len(f.Bytes)/4)
+	for i := range v {
+		v[i] = p.Uint32()
+	}
+	return v, nil
+}
+
+// PackedFixed64 returns the values of a packed repeated fixed64, sfixed64 or double field
+func (f Field) PackedFixed64() ([]uint64, error) {
+	if len(f.Bytes)%8 != 0 {
+		return nil, &decoder.OffsetError{Offset: len(f.Bytes) / 8 * 8, Err: decoder.ErrReadPastEndData}
+	}
+	p := decoder.New(f.Bytes)
+	p.SetLittleEndian()
+	v := make([]uint64, len(f.Bytes)/8)
+	for i := range v {
+		v[i] = p.Uint64()
+	}
+	return v, nil
+}
+
+// DecodeZigZag decodes a zig-zag encoded value
+func DecodeZigZag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
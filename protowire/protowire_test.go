@@ -0,0 +1,154 @@
+package protowire
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	decoder "github.com/kgolding/go-decoder"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestReader(t *testing.T) {
+	msg := mustHex("" +
+		"089601" + // 1: varint 150
+		"120774657374696e67" + // 2: "testing"
+		"1a06038e029ea705" + // 3: packed [3, 270, 86942]
+		"250000c03f" + // 4: float 1.5
+		"29000000000000f83f" + // 5: double 1.5
+		"33" + "0801" + "3b" + "0802" + "3c" + "34" + // 6: group {1: 1, 7: group {1: 2}}
+		"3803" + // 7: sint -2
+		"4a02" + "0801") // 9: embedded message {1: 1}
+
+	r := NewReader(decoder.New(msg))
+	fields, err := r.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 8 {
+		t.Fatalf("expected 8 fields got %d", len(fields))
+	}
+
+	if f := fields[0]; f.Number != 1 || f.Type != VarintType || f.Varint != 150 || f.Offset != 0 {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if f := fields[1]; f.Number != 2 || f.StringValue() != "testing" || hex.EncodeToString(f.Raw) != "120774657374696e67" {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if v, err := fields[2].PackedVarints(); err != nil || !reflect.DeepEqual(v, []uint64{3, 270, 86942}) {
+		t.Errorf("expected [3 270 86942] got %v err %v", v, err)
+	}
+	if f := fields[3]; f.Type != Fixed32Type || f.Float() != 1.5 {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if f := fields[4]; f.Type != Fixed64Type || f.Double() != 1.5 {
+		t.Errorf("unexpected field %+v", f)
+	}
+
+	g := fields[5]
+	if g.Number != 6 || g.Type != StartGroupType || hex.EncodeToString(g.Bytes) != "08013b08023c" {
+		t.Errorf("unexpected group %+v", g)
+	}
+	inner, err := g.Message().All()
+	if err != nil || len(inner) != 2 || inner[0].Varint != 1 || inner[1].Type != StartGroupType {
+		t.Errorf("unexpected group fields %+v err %v", inner, err)
+	}
+
+	if f := fields[6]; f.Number != 7 || f.Sint64() != -2 {
+		t.Errorf("unexpected field %+v", f)
+	}
+	if f := fields[7]; f.Number != 9 || f.Type != BytesType || hex.EncodeToString(f.Bytes) != "0801" {
+		t.Errorf("unexpected field %+v", f)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	r := NewReader(decoder.New(mustHex("0a02080110ff01")))
+	f, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := f.Message().Next()
+	if err != nil || m.Number != 1 || !m.Bool() {
+		t.Errorf("unexpected embedded field %+v err %v", m, err)
+	}
+	f, err = r.Next()
+	if err != nil || f.Number != 2 || f.Int32() != 255 {
+		t.Errorf("unexpected field %+v err %v", f, err)
+	}
+}
+
+func TestPackedFixed(t *testing.T) {
+	f := Field{Bytes: mustHex("01000000ffffffff")}
+	if v, err := f.PackedFixed32(); err != nil || !reflect.DeepEqual(v, []uint32{1, math.MaxUint32}) {
+		t.Errorf("expected [1 MaxUint32] got %v err %v", v, err)
+	}
+	if v, err := f.PackedFixed64(); err != nil || !reflect.DeepEqual(v, []uint64{0xffffffff00000001}) {
+		t.Errorf("expected [0xffffffff00000001] got %v err %v", v, err)
+	}
+	f.Bytes = f.Bytes[:7]
+	if _, err := f.PackedFixed32(); !errors.Is(err, decoder.ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData got %v", err)
+	}
+	if v := DecodeZigZag(4294967295); v != -2147483648 {
+		t.Errorf("expected -2147483648 got %d", v)
+	}
+}
+
+func TestReaderKeepsByteOrder(t *testing.T) {
+	// Field 1 fixed32 of 0x04030201 followed by 0x01, 0x02
+	p := decoder.New([]byte{0x0d, 0x01, 0x02, 0x03, 0x04, 0x01, 0x02})
+	r := NewReader(p)
+	if f, err := r.Next(); err != nil || f.Fixed32 != 0x04030201 {
+		t.Errorf("expected 0x04030201 got %+v err %v", f, err)
+	}
+	if v := p.Uint16(); v != 0x0102 {
+		t.Errorf("expected the packet to still be big endian got 0x%04X", v)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		err    error
+	}{
+		{"0801" + "0096", 2, ErrInvalidFieldNumber},
+		{"0801" + "0e01", 2, ErrInvalidWireType},
+		{"0801" + "1205616263", 2, decoder.ErrReadPastEndData},
+		{"0801" + "0c", 2, ErrUnexpectedEndGroup},
+		{"0801" + "0b0801", 2, decoder.ErrReadPastEndData},
+		{"0801" + "0b0801" + "14", 2, ErrUnexpectedEndGroup},
+		{"0801" + "0b0b0b0b", 4, ErrMaxDepth}, // Errors within groups have the inner offset
+		{"0801" + "08ffffffffffffffffffff01", 2, decoder.ErrInvalidVarint},
+		{"0801" + "0d0000", 2, decoder.ErrReadPastEndData},
+	}
+	for _, test := range tests {
+		p := decoder.New(mustHex(test.input))
+		r := NewReader(p)
+		r.MaxDepth = 2
+		r.Next()
+		_, err := r.Next()
+		var e *decoder.OffsetError
+		if !errors.As(err, &e) || e.Offset != test.offset || !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v at %d got %v", test.input, test.err, test.offset, err)
+		}
+		if p.Index() != 2 || p.Err != nil {
+			t.Errorf("%s: expected reader to be left at 2 got %d err %v", test.input, p.Index(), p.Err)
+		}
+	}
+}
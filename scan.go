@@ -0,0 +1,130 @@
+package decoder
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// ReadMatch matches the regular expression at the internal pointer, returning the match followed by any
+// submatches and increments it accordingly. The match must start at the internal pointer, starting the
+// expression with ^ avoids searching the rest of the data when it does not. On failure Err is set to
+// an *OffsetError wrapping ErrNoMatch at the byte where matching failed
+func (p *Packet) ReadMatch(re *regexp.Regexp) []string {
+	loc := re.FindSubmatchIndex(p.buf[p.idx:p.length])
+	if loc == nil || loc[0] != 0 {
+		p.Err = &OffsetError{Offset: p.idx + matched(re, p.buf[p.idx:p.length]), Err: ErrNoMatch}
+		return nil
+	}
+	v := make([]string, len(loc)/2)
+	for i := range v {
+		if loc[i*2] >= 0 {
+			v[i] = p.text(p.buf[p.idx+loc[i*2] : p.idx+loc[i*2+1]])
+		}
+	}
+	p.idx += loc[1]
+	return v
+}
+
+// matched returns how far into data a match of re starting at the beginning of data got before failing,
+// found by stepping through the compiled expression until every path has failed
+func matched(re *regexp.Regexp, data []byte) int {
+	sre, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	prog, err := syntax.Compile(sre.Simplify())
+	if err != nil {
+		return 0
+	}
+	var threads []uint32
+	seen := make([]bool, len(prog.Inst))
+	var add func(pc uint32, op syntax.EmptyOp)
+	add = func(pc uint32, op syntax.EmptyOp) {
+		if seen[pc] {
+			return
+		}
+		seen[pc] = true
+		switch i := &prog.Inst[pc]; i.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			add(i.Out, op)
+			add(i.Arg, op)
+		case syntax.InstCapture, syntax.InstNop:
+			add(i.Out, op)
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(i.Arg)&^op == 0 {
+				add(i.Out, op)
+			}
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			threads = append(threads, pc)
+		}
+	}
+
+	pos := 0
+	r, size := decodeRune(data)
+	add(uint32(prog.Start), syntax.EmptyOpContext(-1, r))
+	for size > 0 && len(threads) > 0 {
+		current := threads
+		threads = nil
+		for i := range seen {
+			seen[i] = false
+		}
+		next, nextSize := decodeRune(data[pos+size:])
+		op := syntax.EmptyOpContext(r, next)
+		accepted := false
+		for _, pc := range current {
+			i := &prog.Inst[pc]
+			switch {
+			case i.Op == syntax.InstRuneAny, i.Op == syntax.InstRuneAnyNotNL && r != '\n', i.MatchRune(r):
+				accepted = true
+				add(i.Out, op)
+			}
+		}
+		if !accepted {
+			return pos
+		}
+		pos, r, size = pos+size, next, nextSize
+	}
+	return pos
+}
+
+// decodeRune returns the first rune of data and its size, or -1 and 0 if there is no data
+func decodeRune(data []byte) (rune, int) {
+	if len(data) == 0 {
+		return -1, 0
+	}
+	return utf8.DecodeRune(data)
+}
+
+// ReadWhile returns the string at the internal pointer for as long as f returns true and increments it
+// accordingly. If f is false for the first byte Err is set to an *OffsetError wrapping ErrReadNoData
+func (p *Packet) ReadWhile(f func(byte) bool) string {
+	idx := p.idx
+	for idx < p.length && f(p.buf[idx]) {
+		idx++
+	}
+	if idx == p.idx {
+		p.Err = &OffsetError{Offset: idx, Err: ErrReadNoData}
+		return ""
+	}
+	v := p.text(p.buf[p.idx:idx])
+	p.setIndex(idx)
+	return v
+}
+
+// ReadUntil returns the string at the internal pointer up to, but not including, the first byte for which
+// f returns true and increments it accordingly. If no such byte is found Err is set to an *OffsetError
+// wrapping ErrReadPastEndData and the internal pointer is left unchanged
+func (p *Packet) ReadUntil(f func(byte) bool) string {
+	idx := p.idx
+	for idx < p.length && !f(p.buf[idx]) {
+		idx++
+	}
+	if idx == p.length {
+		p.Err = &OffsetError{Offset: idx, Err: ErrReadPastEndData}
+		return ""
+	}
+	v := p.text(p.buf[p.idx:idx])
+	p.setIndex(idx)
+	return v
+}
@@ -0,0 +1,92 @@
+package decoder
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestReadMatch(t *testing.T) {
+	re := regexp.MustCompile(`^\+CSQ: (\d+),(\d+)`)
+
+	p := New([]byte("+CSQ: 21,99\r\nOK"))
+	m := p.ReadMatch(re)
+	if p.Err != nil {
+		t.Fatalf("got unexpected err: %s", p.Err)
+	}
+	if len(m) != 3 || m[0] != "+CSQ: 21,99" || m[1] != "21" || m[2] != "99" {
+		t.Errorf("unexpected submatches %q", m)
+	}
+	if p.Index() != 11 {
+		t.Errorf("expected index 11 got %d", p.Index())
+	}
+
+	// Matches must start at the internal pointer
+	p = New([]byte("xx+CSQ: 21,99"))
+	p.Byte()
+	m = p.ReadMatch(regexp.MustCompile(`\+CSQ`))
+	if m != nil {
+		t.Errorf("expected no match got %q", m)
+	}
+	var e *OffsetError
+	if !errors.As(p.Err, &e) || e.Offset != 1 || !errors.Is(p.Err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch at offset 1 got %v", p.Err)
+	}
+	if p.Index() != 1 {
+		t.Errorf("expected index 1 got %d", p.Index())
+	}
+
+	// The offset is of the byte where matching failed
+	tests := []struct {
+		re     string
+		input  string
+		offset int
+	}{
+		{`^\+CSQ: (\d+),(\d+)`, "+CSQ: 21,x9", 9},
+		{`^\+CSQ: (\d+),(\d+)`, "+CSQ: 21", 8},
+		{`^\+CSQ: (\d+),(\d+)`, "+CMGS: 1", 2},
+		{`^(?i)ok\r\n`, "Ok\rx", 3},
+		{`^(ERROR|OK)$`, "OKAY", 2},
+		{`^é.b`, "éxc", 3},
+	}
+	for _, test := range tests {
+		p = New([]byte("#" + test.input))
+		p.Byte()
+		p.ReadMatch(regexp.MustCompile(test.re))
+		if !errors.As(p.Err, &e) || e.Offset != test.offset+1 {
+			t.Errorf("%q with %q: expected ErrNoMatch at %d got %v", test.re, test.input, test.offset+1, p.Err)
+		}
+	}
+}
+
+func TestReadWhileUntil(t *testing.T) {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isSpace := func(c byte) bool { return c == ' ' }
+
+	p := New([]byte("1234 Banner text"))
+	if s := p.ReadWhile(isDigit); s != "1234" {
+		t.Errorf("expected '1234' got '%s'", s)
+	}
+	p.ReadWhile(isSpace)
+	if s := p.ReadUntil(isSpace); s != "Banner" {
+		t.Errorf("expected 'Banner' got '%s'", s)
+	}
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+
+	p.ReadWhile(isDigit)
+	var e *OffsetError
+	if !errors.As(p.Err, &e) || e.Offset != 11 || !errors.Is(p.Err, ErrReadNoData) {
+		t.Errorf("expected ErrReadNoData at offset 11 got %v", p.Err)
+	}
+
+	p.Err = nil
+	p.ReadUntil(isDigit)
+	if !errors.As(p.Err, &e) || e.Offset != 16 || !errors.Is(p.Err, ErrReadPastEndData) {
+		t.Errorf("expected ErrReadPastEndData at offset 16 got %v", p.Err)
+	}
+	if p.Index() != 11 {
+		t.Errorf("expected index 11 got %d", p.Index())
+	}
+}
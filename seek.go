@@ -0,0 +1,89 @@
+package decoder
+
+import "bytes"
+
+// SeekByte to the next instance of the given byte
+func (p *Packet) SeekByte(b byte) bool {
+	i := bytes.IndexByte(p.buf[p.idx:p.length], b)
+	return p.seekTo(i, 0)
+}
+
+// Seek to the next instance of the given bytes, a match at the internal pointer is not skipped
+func (p *Packet) Seek(b []byte) bool {
+	if len(b) == 0 {
+		p.skipped = 0
+		return false
+	}
+	return p.seekTo(bytes.Index(p.buf[p.idx:p.length], b), 0)
+}
+
+// SeekAfter moves to just past the next instance of the given bytes
+func (p *Packet) SeekAfter(b []byte) bool {
+	if len(b) == 0 {
+		p.skipped = 0
+		return false
+	}
+	return p.seekTo(bytes.Index(p.buf[p.idx:p.length], b), len(b))
+}
+
+// SeekAny moves to the next instance of any of the given patterns, returning the index of the pattern
+// found or -1 if none are found. If several patterns match at the same position the first given wins
+func (p *Packet) SeekAny(patterns ...[]byte) int {
+	window := p.buf[p.idx:p.length]
+	found, match := -1, -1
+	for n, b := range patterns {
+		if len(b) == 0 {
+			continue
+		}
+		// Only search as far as the best match so far
+		end := len(window)
+		if found != -1 {
+			end = found + len(b) - 1
+			if end > len(window) {
+				end = len(window)
+			}
+		}
+		if i := bytes.Index(window[:end], b); i != -1 && (found == -1 || i < found) {
+			found, match = i, n
+		}
+	}
+	p.seekTo(found, 0)
+	return match
+}
+
+// SeekBack moves backwards to the previous instance of the given bytes that starts before the internal pointer
+func (p *Packet) SeekBack(b []byte) bool {
+	p.skipped = 0
+	if len(b) == 0 {
+		return false
+	}
+	end := p.idx + len(b) - 1
+	if end > p.length {
+		end = p.length
+	}
+	i := bytes.LastIndex(p.buf[:end], b)
+	if i == -1 {
+		return false
+	}
+	p.skipped = p.idx - i
+	p.setIndex(i)
+	return true
+}
+
+// Skipped returns the number of bytes passed over before the match by the last successful Seek, SeekByte,
+// SeekAfter or SeekAny, the number of bytes moved back by SeekBack, or 0 if it failed.
+// It is useful for reporting the amount of data lost when resyncing
+func (p *Packet) Skipped() int {
+	return p.skipped
+}
+
+// seekTo moves on by i+extra bytes, where i is the index of a match from the internal pointer or -1
+func (p *Packet) seekTo(i int, extra int) bool {
+	if i == -1 {
+		p.skipped = 0
+		return false
+	}
+	p.skipped = i
+	p.setIndex(p.idx + i + extra)
+	return true
+}
@@ -0,0 +1,90 @@
+package decoder
+
+import "testing"
+
+func TestSeek(t *testing.T) {
+	data := []byte{0xff, 0xff, STX, 0x31, ETX, 0xff, STX, 0x32, ETX}
+
+	tests := []struct {
+		start   int
+		pattern []byte
+		found   bool
+		idx     int
+		skipped int
+	}{
+		{0, []byte{STX}, true, 2, 2},
+		{2, []byte{STX}, true, 2, 0},
+		{3, []byte{STX}, true, 6, 3},
+		{0, []byte{STX, 0x32}, true, 6, 6},
+		{7, []byte{STX}, false, 7, 0},
+		{0, []byte{ETX, 0xff, 0xff}, false, 0, 0}, // Partial match at the end must not read past the data
+		{8, []byte{ETX, 0xff}, false, 8, 0},
+		{0, []byte{}, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		p := New(data)
+		p.Bytes(test.start)
+		found := p.Seek(test.pattern)
+		if found != test.found || p.Index() != test.idx || p.Skipped() != test.skipped {
+			t.Errorf("seek % X from %d: expected %t, %d, %d got %t, %d, %d", test.pattern, test.start,
+				test.found, test.idx, test.skipped, found, p.Index(), p.Skipped())
+		}
+	}
+
+	// A short packet must not panic when the pattern is not present
+	p := New([]byte{0x01})
+	if p.Seek([]byte{0x01, 0x02, 0x03}) {
+		t.Error("expected pattern not to be found")
+	}
+}
+
+func TestSeekAfter(t *testing.T) {
+	p := New([]byte("xxHDR:1234"))
+	if !p.SeekAfter([]byte("HDR:")) {
+		t.Fatal("expected HDR: to be found")
+	}
+	if p.Index() != 6 || p.Skipped() != 2 {
+		t.Errorf("expected index 6 & 2 skipped got %d & %d", p.Index(), p.Skipped())
+	}
+	if v := p.AsciiInt(); v != 1234 {
+		t.Errorf("expected 1234 got %d", v)
+	}
+}
+
+func TestSeekAny(t *testing.T) {
+	p := New([]byte("..ERROR..OK.."))
+	if n := p.SeekAny([]byte("OK"), []byte("ERROR")); n != 1 || p.Index() != 2 {
+		t.Errorf("expected pattern 1 at 2 got %d at %d", n, p.Index())
+	}
+	p.Bytes(1)
+	if n := p.SeekAny([]byte("OK"), []byte("ERROR")); n != 0 || p.Index() != 9 || p.Skipped() != 6 {
+		t.Errorf("expected pattern 0 at 9 skipping 6 got %d at %d skipping %d", n, p.Index(), p.Skipped())
+	}
+	if n := p.SeekAny([]byte("O"), []byte("OK")); n != 0 {
+		t.Errorf("expected first pattern to win a tie got %d", n)
+	}
+	p.Bytes(1)
+	if n := p.SeekAny([]byte("OK"), []byte("ERROR")); n != -1 || p.Index() != 10 {
+		t.Errorf("expected no match at 10 got %d at %d", n, p.Index())
+	}
+}
+
+func TestSeekBack(t *testing.T) {
+	p := New([]byte{STX, 0x31, STX, 0x32, 0x33})
+	p.Bytes(4)
+	if !p.SeekBack([]byte{STX}) || p.Index() != 2 || p.Skipped() != 2 {
+		t.Errorf("expected index 2 & 2 skipped got %d & %d", p.Index(), p.Skipped())
+	}
+	if !p.SeekBack([]byte{STX}) || p.Index() != 0 {
+		t.Errorf("expected index 0 got %d", p.Index())
+	}
+	if p.SeekBack([]byte{STX}) || p.Index() != 0 {
+		t.Errorf("expected no match at 0 got %d", p.Index())
+	}
+
+	p.Bytes(3)
+	if !p.SeekBack([]byte{STX, 0x32, 0x33}) || p.Index() != 2 {
+		t.Errorf("expected match straddling the pointer at 2 got %d", p.Index())
+	}
+}
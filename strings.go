@@ -6,23 +6,15 @@ import (
 
 // StringByDelimiter returns the string at internal pointer using the given delimiter at the end marker
 func (p *Packet) StringByDelimiter(delimiter byte) string {
-	idx := p.idx
-
-	index := bytes.IndexByte(p.buf[idx:], delimiter)
-	if index == -1 {
+	b := p.StringByDelimiterView(delimiter)
+	if b == nil {
 		return ""
 	}
-
-	if p.idx+index >= p.length {
-		p.Err = ErrReadPastEndData
-		return ""
-	}
-
-	p.idx += index + 1
-	return string(p.buf[idx : idx+index])
+	return p.text(b)
 }
 
-// StringPrefixByteLen returns the string at internal pointer using the first byte as it's lenght and increments it accordingly
+// StringPrefixByteLen returns the string at internal pointer using the first byte as it's lenght and increments it accordingly.
+// Unlike PrefixedString a short read leaves the pointer after the length byte, and no max length applies
 func (p *Packet) StringPrefixByteLen() string {
 	if p.idx >= p.length {
 		p.Err = ErrReadPastEndData
@@ -34,30 +26,22 @@ func (p *Packet) StringPrefixByteLen() string {
 		p.Err = ErrReadPastEndData
 		return ""
 	}
-	v := string(p.buf[p.idx : p.idx+l])
+	v := p.text(p.buf[p.idx : p.idx+l])
 	p.idx += l
 	return v
 }
 
 // StringZeroPadded returns the null padded string at internal pointer
 func (p *Packet) StringZeroPadded(fixedLength int) string {
-	if p.idx+fixedLength > p.length {
-		p.Err = ErrReadPastEndData
+	b := p.StringZeroPaddedView(fixedLength)
+	if b == nil {
 		return ""
 	}
-	idx := p.idx
-	p.idx += fixedLength
-
-	b := p.buf[idx:p.idx]
-
-	nullIndex := bytes.IndexByte(b, 0x00)
-	if nullIndex == -1 {
-		return string(b)
-	}
-	return string(p.buf[idx : idx+nullIndex])
+	return p.text(b)
 }
 
-// StringPrefixUint16Len returns the string at internal pointer using the first 2 bytes as it's lenght and increments it accordingly
+// StringPrefixUint16Len returns the string at internal pointer using the first 2 bytes as it's lenght and increments it accordingly.
+// Unlike PrefixedString a short read leaves the pointer after the length, and no max length applies
 func (p *Packet) StringPrefixUint16Len() string {
 	l := int(p.Uint16())
 
@@ -68,22 +52,18 @@ func (p *Packet) StringPrefixUint16Len() string {
 		p.Err = ErrReadPastEndData
 		return ""
 	}
-	v := string(p.buf[p.idx : p.idx+l])
+	v := p.text(p.buf[p.idx : p.idx+l])
 	p.idx += l
 	return v
 }
 
 // CString returns the string at internal pointer (terminated with a 0x00) and increments it accordingly
 func (p *Packet) CString() string {
-	idxStart := p.idx
-	for idx := p.idx; idx < p.length; idx++ {
-		if p.buf[idx] == 0x00 {
-			p.idx = idx + 1
-			return string(p.buf[idxStart:idx])
-		}
+	b := p.CStringView()
+	if b == nil {
+		return ""
 	}
-	p.Err = ErrReadPastEndData
-	return ""
+	return p.text(b)
 }
 
 // StringByWhitelist returns the string at internal pointer using the given whitelist bytes
@@ -92,7 +72,7 @@ func (p *Packet) StringByWhitelist(whitelist []byte) string {
 	var idx int
 	for idx = p.idx; idx < p.length; idx++ {
 		if bytes.IndexByte(whitelist, p.buf[idx]) == -1 {
-			p.idx = idx
+			p.setIndex(idx)
 			break
 		}
 	}
@@ -41,3 +41,35 @@ func TestStringHex(t *testing.T) {
 		}
 	}
 }
+
+func TestStringPrefixShortRead(t *testing.T) {
+	p := New([]byte{0x05, 0x41, 0x42})
+	if s := p.StringPrefixByteLen(); s != "" || p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got '%s' %v", s, p.Err)
+	}
+	if p.Index() != 1 {
+		t.Errorf("expected index to be left after the length got %d", p.Index())
+	}
+
+	p = New([]byte{0x00, 0x05, 0x41, 0x42})
+	if s := p.StringPrefixUint16Len(); s != "" || p.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got '%s' %v", s, p.Err)
+	}
+	if p.Index() != 2 {
+		t.Errorf("expected index to be left after the length got %d", p.Index())
+	}
+
+	// An earlier error does not stop a byte length string being read
+	p = New([]byte{0x02, 0x41, 0x42})
+	p.Err = ErrReadPastEndData
+	if s := p.StringPrefixByteLen(); s != "AB" {
+		t.Errorf("expected 'AB' got '%s'", s)
+	}
+
+	// The max length only applies to PrefixedString
+	p = New([]byte{0x00, 0x03, 0x41, 0x42, 0x43})
+	p.SetMaxLength(2)
+	if s := p.StringPrefixUint16Len(); s != "ABC" || p.Err != nil {
+		t.Errorf("expected 'ABC' got '%s' %v", s, p.Err)
+	}
+}
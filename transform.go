@@ -0,0 +1,138 @@
+package decoder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+var ErrDecodedTooLarge = errors.New("decoded data too large")
+var ErrUnknownTransform = errors.New("unknown transform")
+
+// DefaultMaxDecodedSize is the largest decoded sub-region allowed unless changed with SetMaxDecodedSize
+const DefaultMaxDecodedSize = 16 << 20
+
+// Transform is a compression or text encoding applied to a sub-region of the data
+type Transform int
+
+// Supported transforms
+const (
+	Zlib      Transform = iota // RFC 1950 zlib
+	Gzip                       // RFC 1952 gzip
+	Deflate                    // RFC 1951 raw deflate
+	Base64                     // Standard padded base64
+	Base64URL                  // URL safe padded base64
+	Base32                     // Standard padded base32
+	Hex                        // ASCII hex, upper or lower case
+)
+
+// transforms are applied by wrapping a reader over the encoded data
+var transforms = [...]func(r io.Reader) (io.Reader, error){
+	Zlib: func(r io.Reader) (io.Reader, error) {
+		return zlib.NewReader(r)
+	},
+	Gzip: func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	Deflate: func(r io.Reader) (io.Reader, error) {
+		return flate.NewReader(r), nil
+	},
+	Base64: func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	},
+	Base64URL: func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.URLEncoding, r), nil
+	},
+	Base32: func(r io.Reader) (io.Reader, error) {
+		return base32.NewDecoder(base32.StdEncoding, r), nil
+	},
+	Hex: func(r io.Reader) (io.Reader, error) {
+		return hex.NewDecoder(r), nil
+	},
+}
+
+// SetMaxDecodedSize sets the largest decoded sub-region allowed, protecting against decompression bombs
+func (p *Packet) SetMaxDecodedSize(n int) {
+	p.maxDecoded = n
+}
+
+// Sub returns a new packet over the given number of bytes at the internal pointer and increments it
+// accordingly. The new packet shares the buffer and has the same settings
+func (p *Packet) Sub(length int) *Packet {
+	b := p.Bytes(length)
+	if p.Err != nil {
+		return nil
+	}
+	return p.child(b)
+}
+
+// Decode takes the given number of bytes at the internal pointer, undoes the transform and returns
+// a new packet over the decoded data with the same settings, incrementing the internal pointer accordingly.
+// On failure Err is set to an *OffsetError and the internal pointer is left unchanged
+func (p *Packet) Decode(t Transform, length int) *Packet {
+	idx := p.idx
+	b := p.Bytes(length)
+	if p.Err != nil {
+		return nil
+	}
+	return p.decode(t, idx, b)
+}
+
+// DecodePrefixed is the same as Decode for a length prefixed block
+func (p *Packet) DecodePrefixed(t Transform, kind LenKind) *Packet {
+	idx := p.idx
+	b := p.PrefixedBytes(kind)
+	if p.Err != nil {
+		return nil
+	}
+	return p.decode(t, idx, b)
+}
+
+func (p *Packet) decode(t Transform, idx int, b []byte) *Packet {
+	max := p.maxDecoded
+	if max <= 0 {
+		max = DefaultMaxDecodedSize
+	}
+	v, err := decodeTransform(t, b, max)
+	if err != nil {
+		p.setIndex(idx)
+		p.Err = &OffsetError{Offset: idx, Err: err}
+		return nil
+	}
+	return p.child(v)
+}
+
+func decodeTransform(t Transform, b []byte, max int) ([]byte, error) {
+	if t < 0 || int(t) >= len(transforms) {
+		return nil, ErrUnknownTransform
+	}
+	r, err := transforms[t](bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	// Read one byte more than allowed to detect the limit being exceeded
+	v, err := ioutil.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) > max {
+		return nil, ErrDecodedTooLarge
+	}
+	return v, nil
+}
+
+// child returns a new packet over b with the same settings
+func (p *Packet) child(b []byte) *Packet {
+	c := *p
+	c.Load(b)
+	c.endian = p.endian
+	c.skipped = 0
+	return &c
+}
@@ -0,0 +1,114 @@
+package decoder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+var transformPayload = []byte{0xde, 0xad, 'A', 'B', 0x00}
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, b []byte) []byte {
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		transform Transform
+		encoded   []byte
+	}{
+		{Zlib, compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, transformPayload)},
+		{Gzip, compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, transformPayload)},
+		{Deflate, compress(t, func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}, transformPayload)},
+		{Base64, []byte(base64.StdEncoding.EncodeToString(transformPayload))},
+		{Base64URL, []byte(base64.URLEncoding.EncodeToString(transformPayload))},
+		{Base32, []byte(base32.StdEncoding.EncodeToString(transformPayload))},
+		{Hex, []byte(hex.EncodeToString(transformPayload))},
+	}
+
+	for _, test := range tests {
+		data := append([]byte{STX, byte(len(test.encoded))}, test.encoded...)
+		data = append(data, ETX)
+
+		p := New(data)
+		p.ExpectByte(STX)
+		c := p.DecodePrefixed(test.transform, LenUint8)
+		p.ExpectByte(ETX)
+		if p.Err != nil {
+			t.Errorf("transform %d: got unexpected err: %s", test.transform, p.Err)
+			continue
+		}
+		if v := c.Uint16(); v != 0xdead {
+			t.Errorf("transform %d: expected 0xdead got %X", test.transform, v)
+		}
+		if s := c.CString(); s != "AB" {
+			t.Errorf("transform %d: expected 'AB' got '%s'", test.transform, s)
+		}
+		if c.Err != nil || !c.EOF() {
+			t.Errorf("transform %d: expected EOF got err %v", test.transform, c.Err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	bomb := compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, make([]byte, 1000))
+	p := New(append([]byte{0xff}, bomb...))
+	p.Byte()
+	p.SetMaxDecodedSize(999)
+	if c := p.Decode(Zlib, len(bomb)); c != nil {
+		t.Error("expected nil packet")
+	}
+	var e *OffsetError
+	if !errors.As(p.Err, &e) || e.Offset != 1 || e.Err != ErrDecodedTooLarge {
+		t.Errorf("expected ErrDecodedTooLarge at 1 got %v", p.Err)
+	}
+	if p.Index() != 1 {
+		t.Errorf("expected index to be left at 1 got %d", p.Index())
+	}
+
+	p.Err = nil
+	p.SetMaxDecodedSize(1000)
+	if c := p.Decode(Zlib, len(bomb)); c == nil || c.RemainingLength() != 1000 {
+		t.Errorf("expected 1000 bytes got err %v", p.Err)
+	}
+
+	p = New([]byte("zz"))
+	p.Decode(Hex, 2)
+	if !errors.As(p.Err, &e) || e.Offset != 0 {
+		t.Errorf("expected hex error at 0 got %v", p.Err)
+	}
+}
+
+func TestSub(t *testing.T) {
+	p := New([]byte{0x01, 0x02, 0x03, 0x04})
+	p.SetLittleEndian()
+	c := p.Sub(2)
+	if v := c.Uint16(); v != 0x0201 {
+		t.Errorf("expected little endian 0x0201 got %X", v)
+	}
+	c.Byte()
+	if c.Err != ErrReadPastEndData {
+		t.Errorf("expected ErrReadPastEndData got %v", c.Err)
+	}
+	if v := p.Uint16(); v != 0x0403 || p.Err != nil {
+		t.Errorf("expected 0x0403 got %X err %v", v, p.Err)
+	}
+}
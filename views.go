@@ -0,0 +1,134 @@
+package decoder
+
+import (
+	"bytes"
+	"sync"
+)
+
+// The *View functions return slices that alias the packet's buffer, they do not allocate but are only
+// valid for as long as the buffer passed to New is left unmodified. Text encodings are not applied to views.
+// The *Copy functions always return a newly allocated slice that is safe to keep.
+
+// BytesView returns the given number of bytes at the internal pointer and increments it accordingly, the same as Bytes
+func (p *Packet) BytesView(length int) []byte {
+	return p.Bytes(length)
+}
+
+// BytesCopy returns a copy of the given number of bytes at the internal pointer and increments it accordingly
+func (p *Packet) BytesCopy(length int) []byte {
+	return copyBytes(p.Bytes(length))
+}
+
+// PrefixedBytesCopy returns a copy of the bytes at the internal pointer using the given length prefix kind
+// and increments it accordingly
+func (p *Packet) PrefixedBytesCopy(kind LenKind) []byte {
+	return copyBytes(p.PrefixedBytes(kind))
+}
+
+// CStringView returns the bytes at internal pointer up to a terminating 0x00 (which is not included)
+// and increments it accordingly
+func (p *Packet) CStringView() []byte {
+	idxStart := p.idx
+	for idx := p.idx; idx < p.length; idx++ {
+		if p.buf[idx] == 0x00 {
+			p.setIndex(idx + 1)
+			return p.buf[idxStart:idx]
+		}
+	}
+	p.Err = ErrReadPastEndData
+	return nil
+}
+
+// StringZeroPaddedView returns the null padded bytes at internal pointer without the padding
+func (p *Packet) StringZeroPaddedView(fixedLength int) []byte {
+	if p.idx+fixedLength > p.length {
+		p.Err = ErrReadPastEndData
+		return nil
+	}
+	b := p.buf[p.idx : p.idx+fixedLength]
+	p.idx += fixedLength
+
+	nullIndex := bytes.IndexByte(b, 0x00)
+	if nullIndex == -1 {
+		return b
+	}
+	return b[:nullIndex]
+}
+
+// StringByDelimiterView returns the bytes at internal pointer using the given delimiter as the end marker
+func (p *Packet) StringByDelimiterView(delimiter byte) []byte {
+	idx := p.idx
+
+	index := bytes.IndexByte(p.buf[idx:], delimiter)
+	if index == -1 {
+		return nil
+	}
+
+	if p.idx+index >= p.length {
+		p.Err = ErrReadPastEndData
+		return nil
+	}
+
+	p.idx += index + 1
+	return p.buf[idx : idx+index]
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// Interner keeps a single copy of frequently repeated strings, such as device IDs, so that string
+// reads of a value already seen do not allocate. An Interner is safe to share between packets
+// and goroutines
+type Interner struct {
+	mu      sync.Mutex
+	strings map[TextEncoding]map[string]string
+	count   int
+	max     int
+}
+
+// NewInterner returns an Interner that keeps at most max strings, 0 means no limit
+func NewInterner(max int) *Interner {
+	return &Interner{
+		strings: make(map[TextEncoding]map[string]string),
+		max:     max,
+	}
+}
+
+// SetInterner makes future string reads use the given Interner, nil turns interning off
+func (p *Packet) SetInterner(in *Interner) {
+	p.interner = in
+}
+
+// Len returns the number of strings held
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.count
+}
+
+func (in *Interner) intern(e TextEncoding, b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	m := in.strings[e]
+	if s, ok := m[string(b)]; ok { // The compiler avoids allocating for this lookup
+		return s
+	}
+	s := decodeText(e, b)
+	if in.max > 0 && in.count >= in.max {
+		return s
+	}
+	if m == nil {
+		m = make(map[string]string)
+		in.strings[e] = m
+	}
+	m[string(b)] = s
+	in.count++
+	return s
+}
@@ -0,0 +1,85 @@
+package decoder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestViewsAndCopies(t *testing.T) {
+	buf := []byte{0x31, 0x32, 0x00, 0x33, 0x00, 0x00, 0x34, '|', 0x35, 0x36}
+	p := New(buf)
+
+	c := p.CStringView()
+	z := p.StringZeroPaddedView(3)
+	d := p.StringByDelimiterView('|')
+	b := p.BytesCopy(2)
+	if p.Err != nil {
+		t.Errorf("got unexpected err: %s", p.Err)
+	}
+
+	for _, test := range []struct {
+		got, expect []byte
+	}{
+		{c, []byte("12")},
+		{z, []byte("3")},
+		{d, []byte("4")},
+		{b, []byte("56")},
+	} {
+		if !bytes.Equal(test.got, test.expect) {
+			t.Errorf("expected % X got % X", test.expect, test.got)
+		}
+	}
+
+	// Views alias the buffer, copies do not
+	buf[0], buf[8] = 'x', 'x'
+	if c[0] != 'x' {
+		t.Errorf("expected view to alias the buffer")
+	}
+	if b[0] != 0x35 {
+		t.Errorf("expected copy not to alias the buffer")
+	}
+}
+
+func TestViewsDoNotAllocate(t *testing.T) {
+	buf := []byte{0x31, 0x32, 0x00, 0x33, 0x00, 0x00}
+	p := New(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Reset()
+		p.CStringView()
+		p.StringZeroPaddedView(3)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations got %.0f", allocs)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner(1)
+	buf := []byte("DEV1\x00DEV2\x00")
+
+	p := New(buf)
+	p.SetInterner(in)
+	s1 := p.CString()
+	s2 := p.CString()
+	if s1 != "DEV1" || s2 != "DEV2" {
+		t.Errorf("expected DEV1 & DEV2 got %s & %s", s1, s2)
+	}
+	if in.Len() != 1 {
+		t.Errorf("expected 1 interned string got %d", in.Len())
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Reset()
+		p.CString()
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations for an interned string got %.0f", allocs)
+	}
+
+	p = New([]byte{0xe9, 0x00})
+	p.SetInterner(NewInterner(0))
+	p.SetEncoding(EncodingLatin1)
+	if s := p.CString(); s != "é" {
+		t.Errorf("expected 'é' got '%s'", s)
+	}
+}
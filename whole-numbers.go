@@ -32,14 +32,13 @@ func (p *Packet) Uint24() uint32 {
 		p.Err = ErrReadPastEndData
 		return 0
 	}
-	// copy 3 bytes into the middle of a 5 byte slice making it easy to read it as little or big endian
-	b := make([]byte, 5)
-	copy(b[1:], p.buf[p.idx:p.idx+3])
+	// combine the bytes directly, going via p.endian would need a heap allocated slice
+	b := p.buf[p.idx : p.idx+3]
 	p.idx += 3
 	if p.endian == binary.BigEndian {
-		return p.endian.Uint32(b[0:])
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
 	}
-	return p.endian.Uint32(b[1:])
+	return uint32(b[2])<<16 | uint32(b[1])<<8 | uint32(b[0])
 }
 
 // Uint32 returns the value at the internal pointer and increments it accordingly
@@ -63,3 +62,41 @@ func (p *Packet) Uint64() uint64 {
 	p.idx += 8
 	return v
 }
+
+// Uvarint returns the unsigned LEB128 varint (as used by protobuf) at the internal pointer and increments it accordingly
+func (p *Packet) Uvarint() uint64 {
+	if p.idx >= p.length {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	v, n := binary.Uvarint(p.buf[p.idx:p.length])
+	if n == 0 {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	if n < 0 {
+		p.Err = ErrInvalidVarint
+		return 0
+	}
+	p.idx += n
+	return v
+}
+
+// Varint returns the zig-zag encoded signed varint at the internal pointer and increments it accordingly
+func (p *Packet) Varint() int64 {
+	if p.idx >= p.length {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	v, n := binary.Varint(p.buf[p.idx:p.length])
+	if n == 0 {
+		p.Err = ErrReadPastEndData
+		return 0
+	}
+	if n < 0 {
+		p.Err = ErrInvalidVarint
+		return 0
+	}
+	p.idx += n
+	return v
+}